@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"os"
+	"snake-game/internal/conformance"
+	"snake-game/internal/game/headless"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"time"
+)
+
+func main() {
+	mode := flag.String("mode", "master", "role of the target: master (we play NORMAL) or normal (we play MASTER)")
+	target := flag.String("target", "", "target host:port")
+	group := flag.String("group", network.DefaultMulticastGroup, "multicast group used to announce the fake game")
+	self := flag.Bool("self", false, "run against an in-process network.Manager instead of -target")
+	joinTimeout := flag.Duration("join-timeout", 30*time.Second, "how long the fake master waits for a JoinMsg")
+	flag.Parse()
+
+	var report *conformance.Report
+	switch *mode {
+	case "master":
+		addr := resolveTarget(*target, *self)
+		if *self {
+			stop, selfAddr := startSelfMaster()
+			defer stop()
+			addr = selfAddr
+		}
+		r, err := conformance.CheckMaster(addr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		report = r
+	case "normal":
+		groupAddr, err := net.ResolveUDPAddr("udp", *group)
+		if err != nil {
+			log.Fatalf("Failed to resolve group: %v", err)
+		}
+		var addr *net.UDPAddr
+		if *target != "" {
+			addr = resolveTarget(*target, false)
+		}
+		var client *network.Manager
+		if *self {
+			client = startSelfClient()
+			defer client.Close()
+			addr = loopback(client.LocalAddr())
+		}
+		suite, err := conformance.NewNormalSuite(groupAddr, addr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if client != nil {
+			go joinWhenAnnounced(client)
+		} else {
+			log.Printf("Fake game announced from %s, join it from the client under test", suite.MasterAddr())
+		}
+		report = suite.Run(*joinTimeout)
+	default:
+		log.Fatalf("Unknown mode %q", *mode)
+	}
+	report.Print(os.Stdout)
+	if report.Failed() > 0 {
+		os.Exit(1)
+	}
+}
+
+func resolveTarget(target string, self bool) *net.UDPAddr {
+	if self {
+		return nil
+	}
+	if target == "" {
+		log.Fatal("Either -target or -self is required")
+	}
+	addr, err := net.ResolveUDPAddr("udp", target)
+	if err != nil {
+		log.Fatalf("Failed to resolve target: %v", err)
+	}
+	return addr
+}
+
+func loopback(addr *net.UDPAddr) *net.UDPAddr {
+	return &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: addr.Port}
+}
+
+func startSelfMaster() (func(), *net.UDPAddr) {
+	mgr := network.NewNetworkManager(prt.NodeRole_NORMAL, nil)
+	mgr.SetMulticastInterface("")
+	if err := mgr.Start(); err != nil {
+		log.Fatalf("Failed to start in-process master: %v", err)
+	}
+	cfg := &prt.GameConfig{Width: 30, Height: 30, FoodStatic: 3, StateDelayMs: 200}
+	host := headless.NewHost("self-check", "master", cfg, mgr)
+	host.Start()
+	return func() {
+		host.Stop()
+		mgr.Close()
+	}, loopback(mgr.LocalAddr())
+}
+
+type selfState struct{}
+
+func (selfState) OnGameStateReceived(*prt.GameState) {}
+
+func startSelfClient() *network.Manager {
+	mgr := network.NewNetworkManager(prt.NodeRole_NORMAL, nil)
+	mgr.SetMulticastInterface("")
+	mgr.SetGameStateListener(selfState{})
+	if err := mgr.Start(); err != nil {
+		log.Fatalf("Failed to start in-process client: %v", err)
+	}
+	return mgr
+}
+
+func joinWhenAnnounced(client *network.Manager) {
+	for {
+		info, ok := client.LookupGame("conformance")
+		if ok {
+			joined, err := client.SendJoinRequest(prt.PlayerType_ROBOT, "self-client", info, prt.NodeRole_NORMAL)
+			if err != nil {
+				log.Printf("In-process client failed to join: %v", err)
+				return
+			}
+			<-joined
+			client.SetGameAnnouncement(info.Announcement)
+			client.SetActivityManager(info.Announcement.GetConfig().GetStateDelayMs())
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
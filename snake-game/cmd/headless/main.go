@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"snake-game/internal/game/config"
+	"snake-game/internal/game/headless"
+	"snake-game/internal/game/maps"
+	"snake-game/internal/game/match"
+	"snake-game/internal/game/saves"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"snake-game/internal/spectator"
+	"strings"
+)
+
+func main() {
+	gameName := flag.String("game", "headless", "name of the announced game")
+	playerName := flag.String("player", "master", "name of the master's robot player")
+	configPath := flag.String("config", os.Getenv("CONFIG_PATH"), "game config file")
+	mapName := flag.String("map", os.Getenv("MAP"), "bundled map name or map file")
+	group := flag.String("group", os.Getenv("MULTICAST_GROUP"), "multicast group, e.g. v6")
+	iface := flag.String("iface", os.Getenv("MULTICAST_IFACE"), "network interface for the multicast group")
+	listen := flag.String("listen", os.Getenv("UNICAST_ADDR"), "bind the game socket here, e.g. :9192, so players can connect directly")
+	spectate := flag.String("spectate", os.Getenv("SPECTATE_ADDR"), "serve the spectator page on this address")
+	rounds := flag.Int("rounds", 0, "play a match of this many rounds instead of an endless game")
+	roundTime := flag.Duration("round-time", 0, "match mode: length of a round, e.g. 2m")
+	scoreTarget := flag.Int("score-target", 0, "match mode: the first player to reach this score wins")
+	countdown := flag.Duration("countdown", match.DefaultCountdown, "match mode: countdown before each round")
+	resume := flag.String("resume", "", "host a saved game again, by game name or save file")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	mgr := network.NewNetworkManager(prt.NodeRole_NORMAL, nil)
+	mgr.SetMulticastGroup(*group)
+	mgr.SetMulticastInterface(*iface)
+	mgr.SetListenAddr(*listen)
+	if err := mgr.Start(); err != nil {
+		log.Fatalf("Failed to start network manager: %v", err)
+	}
+	defer mgr.Close()
+
+	host := headless.NewHost(*gameName, *playerName, cfg, mgr)
+	store, err := saves.DefaultStore()
+	if err != nil {
+		log.Printf("Saving disabled: %v", err)
+	} else {
+		host.SetSaveStore(store)
+	}
+	if *resume != "" {
+		if store == nil {
+			log.Fatal("Cannot resume without a saves directory")
+		}
+		load := store.Load
+		if strings.ContainsAny(*resume, `/\`) || filepath.Ext(*resume) == ".json" {
+			load = saves.LoadFile
+		}
+		saved, err := load(*resume)
+		if err != nil {
+			log.Fatalf("Failed to load saved game: %v", err)
+		}
+		if err := host.Resume(saved); err != nil {
+			log.Fatalf("Failed to resume saved game: %v", err)
+		}
+		*gameName = saved.Game
+		cfg = host.GetLogic().Config
+	} else if *mapName != "" {
+		gameMap, err := maps.Load(*mapName)
+		if err != nil {
+			log.Fatalf("Failed to load map: %v", err)
+		}
+		host.SetMap(gameMap)
+	}
+	if *spectate != "" {
+		server := spectator.NewServer()
+		if err := server.Start(*spectate); err != nil {
+			log.Fatal(err)
+		}
+		defer server.Close()
+		server.SetGame(*gameName, cfg)
+		host.SetStateObserver(server)
+	}
+	host.SetMatch(match.Options{
+		Rounds:      *rounds,
+		Duration:    *roundTime,
+		ScoreTarget: int32(*scoreTarget),
+		Countdown:   *countdown,
+	})
+	host.Start()
+	log.Printf("Hosting '%s' on %s, type 'help' for admin commands", *gameName, mgr.LocalAddr())
+	go host.RunCommands(os.Stdin, os.Stdout)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	select {
+	case <-host.Ended():
+	case <-interrupt:
+		host.Exit()
+	}
+}
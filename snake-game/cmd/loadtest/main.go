@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"snake-game/internal/game/bots"
+	"snake-game/internal/loadtest"
+	prt "snake-game/internal/proto/gen"
+	"strings"
+	"time"
+)
+
+func main() {
+	clients := flag.Int("clients", 20, "number of simulated clients kept in the game")
+	duration := flag.Duration("duration", 30*time.Second, "how long to run")
+	target := flag.String("target", "", "master host:port to test; empty hosts one in-process and measures its ticks and CPU")
+	strategy := flag.String("bot", "random", "steering policy, one of "+strings.Join(bots.Names(), ", "))
+	leaveRate := flag.Float64("leave-rate", 0.02, "chance per second that a client leaves")
+	timeoutRate := flag.Float64("timeout-rate", 0.01, "chance per second that a client falls silent")
+	width := flag.Int("width", 60, "in-process master: field width")
+	height := flag.Int("height", 60, "in-process master: field height")
+	food := flag.Int("food", 10, "in-process master: food_static")
+	delay := flag.Int("delay", 100, "in-process master: state_delay_ms")
+	seed := flag.Uint64("seed", uint64(time.Now().UnixNano()), "seed for the clients' randomness")
+	jsonPath := flag.String("json", "", "write the report as JSON to this file")
+	verbose := flag.Bool("v", false, "show the logs of the clients and the in-process master")
+	flag.Parse()
+
+	opts := loadtest.Options{
+		Clients:     *clients,
+		Duration:    *duration,
+		Strategy:    *strategy,
+		LeaveRate:   *leaveRate,
+		TimeoutRate: *timeoutRate,
+		Seed:        *seed,
+		Config: &prt.GameConfig{
+			Width:        int32(*width),
+			Height:       int32(*height),
+			FoodStatic:   int32(*food),
+			StateDelayMs: int32(*delay),
+		},
+	}
+	if *target != "" {
+		addr, err := net.ResolveUDPAddr("udp", *target)
+		if err != nil {
+			log.Fatalf("Failed to resolve target: %v", err)
+		}
+		opts.Target = addr
+	}
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	report, err := loadtest.Run(opts)
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("seed %d\n", *seed)
+	report.WriteText(os.Stdout)
+	if *jsonPath != "" {
+		if err := writeFile(*jsonPath, report.WriteJSON); err != nil {
+			log.Fatalf("Failed to write JSON: %v", err)
+		}
+	}
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
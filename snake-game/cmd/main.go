@@ -1,10 +1,36 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
 	"snake-game/internal/game/core"
+	"snake-game/internal/game/terminal"
+	"snake-game/internal/game/window"
 )
 
 func main() {
-	game := core.NewGame()
+	connect := flag.String("connect", "", "ask the master at host:port for its games instead of waiting for multicast")
+	group := flag.String("group", os.Getenv("MULTICAST_GROUP"), "multicast group, e.g. v6")
+	iface := flag.String("iface", os.Getenv("MULTICAST_IFACE"), "network interface for the multicast group")
+	listen := flag.String("listen", os.Getenv("UNICAST_ADDR"), "bind the game socket here, e.g. :9192, so players can connect directly")
+	ui := flag.String("ui", "window", "front-end: window, or terminal to play in the terminal")
+	flag.Parse()
+
+	var frontend core.Frontend
+	switch *ui {
+	case "window":
+		frontend = window.New()
+	case "terminal":
+		frontend = terminal.NewFrontend()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown -ui %q, want window or terminal\n", *ui)
+		os.Exit(2)
+	}
+	game := core.NewGame(frontend)
+	game.SetMulticastGroup(*group)
+	game.SetMulticastInterface(*iface)
+	game.SetListenAddr(*listen)
+	game.SetConnectAddr(*connect)
 	game.Start()
 }
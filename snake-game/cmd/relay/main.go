@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"snake-game/internal/relay"
+	"time"
+)
+
+func main() {
+	gameName := flag.String("game", "", "name of the game to relay")
+	playerName := flag.String("player", "relay", "name the relay joins the game under")
+	connect := flag.String("connect", "", "ask the master at host:port for its games instead of waiting for multicast")
+	listen := flag.String("listen", relay.DefaultListenAddr, "address viewers connect to")
+	maxViewers := flag.Int("max-viewers", relay.DefaultMaxViewers, "most viewers served at once")
+	announce := flag.String("announce", "", "also announce the relayed game on this multicast group")
+	group := flag.String("group", os.Getenv("MULTICAST_GROUP"), "multicast group the master announces on, e.g. v6")
+	iface := flag.String("iface", os.Getenv("MULTICAST_IFACE"), "network interface for the multicast group")
+	wait := flag.Duration("wait", 5*time.Second, "how long to look for the game")
+	flag.Parse()
+	if *gameName == "" {
+		log.Fatal("Pass the game to relay with -game")
+	}
+
+	mgr := network.NewNetworkManager(prt.NodeRole_NORMAL, nil)
+	mgr.SetMulticastGroup(*group)
+	mgr.SetMulticastInterface(*iface)
+	if err := mgr.Start(); err != nil {
+		log.Fatalf("Failed to start network manager: %v", err)
+	}
+	defer mgr.Close()
+	if *connect != "" {
+		if _, err := mgr.ConnectTo(*connect, *wait); err != nil {
+			log.Fatalf("Failed to reach the master: %v", err)
+		}
+	} else if !waitForGame(mgr, *gameName, *wait) {
+		log.Fatalf("Game '%s' not announced within %s", *gameName, *wait)
+	}
+
+	r := relay.New(mgr, *gameName, *playerName)
+	r.SetMaxViewers(*maxViewers)
+	if *announce != "" {
+		if err := r.SetAnnounceGroup(*announce); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := r.Start(*listen); err != nil {
+		log.Fatalf("Failed to relay '%s': %v", *gameName, err)
+	}
+	defer r.Close()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	select {
+	case <-r.Ended():
+	case <-interrupt:
+	}
+	log.Printf("Stopped relaying with %d viewers, %d steers ignored", r.Viewers(), r.RejectedSteers())
+}
+
+func waitForGame(mgr *network.Manager, gameName string, wait time.Duration) bool {
+	deadline := time.Now().Add(wait)
+	for time.Now().Before(deadline) {
+		if _, ok := mgr.LookupGame(gameName); ok {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
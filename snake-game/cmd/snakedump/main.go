@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"snake-game/internal/capture"
+	"snake-game/internal/network"
+	"time"
+)
+
+func main() {
+	group := flag.String("group", network.DefaultMulticastGroup, "multicast group to join, empty to disable")
+	iface := flag.String("iface", "", "network interface for the multicast group")
+	listen := flag.String("listen", "", "unicast address to bind, e.g. :9193")
+	jsonOut := flag.Bool("json", false, "print the timeline as JSON lines")
+	noField := flag.Bool("nofield", false, "do not render the field for state messages")
+	readPath := flag.String("read", "", "replay a capture file instead of listening")
+	writePath := flag.String("write", "", "save received datagrams to a capture file")
+	flag.Parse()
+
+	printer := NewPrinter(os.Stdout, *jsonOut, !*noField)
+	if *readPath != "" {
+		if err := replay(*readPath, printer); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var writer *capture.Writer
+	if *writePath != "" {
+		w, err := capture.Create(*writePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer w.Close()
+		writer = w
+	}
+	records := make(chan *capture.Record, 64)
+	if *group != "" {
+		conn, err := listenMulticast(*group, *iface)
+		if err != nil {
+			log.Fatalf("Failed to join multicast group: %v", err)
+		}
+		defer conn.Close()
+		go readLoop(conn, capture.SourceMulticast, records)
+	}
+	if *listen != "" {
+		addr, err := net.ResolveUDPAddr("udp", *listen)
+		if err != nil {
+			log.Fatalf("Failed to resolve listen address: %v", err)
+		}
+		conn, err := net.ListenUDP("udp", addr)
+		if err != nil {
+			log.Fatalf("Failed to bind unicast socket: %v", err)
+		}
+		defer conn.Close()
+		log.Printf("Listening for unicast on %s", conn.LocalAddr())
+		go readLoop(conn, capture.SourceUnicast, records)
+	}
+	if *group == "" && *listen == "" {
+		log.Fatal("Nothing to listen on: set -group and/or -listen")
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	for {
+		select {
+		case record := <-records:
+			if writer != nil {
+				if err := writer.Write(record); err != nil {
+					log.Printf("Error saving record: %v", err)
+				}
+			}
+			printer.Print(record)
+		case <-interrupt:
+			return
+		}
+	}
+}
+
+func listenMulticast(group, ifaceName string) (*net.UDPConn, error) {
+	groupAddr, err := net.ResolveUDPAddr("udp", group)
+	if err != nil {
+		return nil, err
+	}
+	var iface *net.Interface
+	if ifaceName != "" {
+		if iface, err = net.InterfaceByName(ifaceName); err != nil {
+			return nil, err
+		}
+	}
+	network := "udp4"
+	if groupAddr.IP.To4() == nil {
+		network = "udp6"
+	}
+	return net.ListenMulticastUDP(network, iface, groupAddr)
+}
+
+func readLoop(conn *net.UDPConn, source string, records chan<- *capture.Record) {
+	buf := make([]byte, 65536)
+	for {
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Printf("Error reading from %s socket: %v", source, err)
+			continue
+		}
+		data := make([]byte, n)
+		copy(data, buf[:n])
+		records <- &capture.Record{Time: time.Now(), Source: source, From: addr.String(), Data: data}
+	}
+}
+
+func replay(path string, printer *Printer) error {
+	reader, err := capture.Open(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		printer.Print(record)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	"io"
+	"snake-game/internal/capture"
+	"snake-game/internal/game/logic"
+	"snake-game/internal/game/ui"
+	prt "snake-game/internal/proto/gen"
+	"strings"
+)
+
+type Printer struct {
+	out        io.Writer
+	jsonOut    bool
+	showField  bool
+	lastConfig *prt.GameConfig
+}
+
+type jsonEntry struct {
+	Time       string          `json:"time"`
+	Source     string          `json:"source"`
+	From       string          `json:"from"`
+	Type       string          `json:"type"`
+	MsgSeq     int64           `json:"msg_seq"`
+	SenderID   int32           `json:"sender_id,omitempty"`
+	ReceiverID int32           `json:"receiver_id,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Message    json.RawMessage `json:"message,omitempty"`
+}
+
+func NewPrinter(out io.Writer, jsonOut bool, showField bool) *Printer {
+	return &Printer{out: out, jsonOut: jsonOut, showField: showField}
+}
+
+func (p *Printer) Print(record *capture.Record) {
+	var msg prt.GameMessage
+	err := proto.Unmarshal(record.Data, &msg)
+	if err == nil {
+		for _, game := range msg.GetAnnouncement().GetGames() {
+			p.lastConfig = game.GetConfig()
+		}
+	}
+	if p.jsonOut {
+		p.printJSON(record, &msg, err)
+		return
+	}
+	timestamp := record.Time.Format("15:04:05.000")
+	if err != nil {
+		fmt.Fprintf(p.out, "%s %-9s %-21s MALFORMED (%d bytes): %v\n", timestamp, record.Source, record.From, len(record.Data), err)
+		return
+	}
+	fmt.Fprintf(p.out, "%s %-9s %-21s %-12s seq=%d sender=%d receiver=%d %s\n",
+		timestamp, record.Source, record.From, messageType(&msg), msg.GetMsgSeq(),
+		msg.GetSenderId(), msg.GetReceiverId(), summary(&msg))
+	// Snakes can only be laid out on a field of known size, so states seen
+	// before the first announcement are printed as messages only.
+	width, height := p.lastConfig.GetWidth(), p.lastConfig.GetHeight()
+	if state := msg.GetState().GetState(); state != nil && p.showField && width > 0 && height > 0 {
+		state = logic.DecodeState(state, width, height)
+		fmt.Fprint(p.out, indent(ui.RenderField(state, width, height)))
+	}
+}
+
+func (p *Printer) printJSON(record *capture.Record, msg *prt.GameMessage, decodeErr error) {
+	entry := jsonEntry{
+		Time:   record.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		Source: record.Source,
+		From:   record.From,
+	}
+	if decodeErr != nil {
+		entry.Type = "MALFORMED"
+		entry.Error = decodeErr.Error()
+	} else {
+		entry.Type = messageType(msg)
+		entry.MsgSeq = msg.GetMsgSeq()
+		entry.SenderID = msg.GetSenderId()
+		entry.ReceiverID = msg.GetReceiverId()
+		if raw, err := protojson.Marshal(msg); err == nil {
+			entry.Message = raw
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(p.out, string(data))
+}
+
+func messageType(msg *prt.GameMessage) string {
+	switch {
+	case msg.GetPing() != nil:
+		return "PING"
+	case msg.GetSteer() != nil:
+		return "STEER"
+	case msg.GetAck() != nil:
+		return "ACK"
+	case msg.GetState() != nil:
+		return "STATE"
+	case msg.GetAnnouncement() != nil:
+		return "ANNOUNCEMENT"
+	case msg.GetJoin() != nil:
+		return "JOIN"
+	case msg.GetError() != nil:
+		return "ERROR"
+	case msg.GetRoleChange() != nil:
+		return "ROLE_CHANGE"
+	case msg.GetDiscover() != nil:
+		return "DISCOVER"
+	}
+	return "UNKNOWN"
+}
+
+func summary(msg *prt.GameMessage) string {
+	switch {
+	case msg.GetSteer() != nil:
+		return msg.GetSteer().GetDirection().String()
+	case msg.GetState() != nil:
+		state := msg.GetState().GetState()
+		alive, zombie := 0, 0
+		for _, snake := range state.GetSnakes() {
+			if snake.GetState() == prt.GameState_Snake_ALIVE {
+				alive++
+			} else {
+				zombie++
+			}
+		}
+		players := make([]string, 0)
+		for _, player := range state.GetPlayers().GetPlayers() {
+			players = append(players, fmt.Sprintf("%s#%d:%s:%d", player.GetName(), player.GetId(), player.GetRole(), player.GetScore()))
+		}
+		return fmt.Sprintf("order=%d snakes=%d/%d foods=%d players=[%s]",
+			state.GetStateOrder(), alive, zombie, len(state.GetFoods()), strings.Join(players, " "))
+	case msg.GetAnnouncement() != nil:
+		games := make([]string, 0)
+		for _, game := range msg.GetAnnouncement().GetGames() {
+			cfg := game.GetConfig()
+			games = append(games, fmt.Sprintf("%q %dx%d food=%d delay=%dms players=%d can_join=%t",
+				game.GetGameName(), cfg.GetWidth(), cfg.GetHeight(), cfg.GetFoodStatic(), cfg.GetStateDelayMs(),
+				len(game.GetPlayers().GetPlayers()), game.GetCanJoin()))
+		}
+		return strings.Join(games, "; ")
+	case msg.GetJoin() != nil:
+		join := msg.GetJoin()
+		return fmt.Sprintf("player=%q game=%q role=%s type=%s",
+			join.GetPlayerName(), join.GetGameName(), join.GetRequestedRole(), join.GetPlayerType())
+	case msg.GetError() != nil:
+		return fmt.Sprintf("%q", msg.GetError().GetErrorMessage())
+	case msg.GetRoleChange() != nil:
+		return fmt.Sprintf("sender_role=%s receiver_role=%s",
+			msg.GetRoleChange().GetSenderRole(), msg.GetRoleChange().GetReceiverRole())
+	}
+	return ""
+}
+
+func indent(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	return "    " + strings.Join(lines, "\n    ") + "\n"
+}
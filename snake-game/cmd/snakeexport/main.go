@@ -0,0 +1,223 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"snake-game/internal/capture"
+	"snake-game/internal/export"
+	"snake-game/internal/game/logic"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"sync"
+	"time"
+)
+
+type recording struct {
+	mu     sync.Mutex
+	states []*prt.GameState
+	config *prt.GameConfig
+	frames chan struct{}
+	// encoded is set for states read straight off the wire, whose snakes
+	// are still head and offsets.
+	encoded bool
+}
+
+func (r *recording) OnGameStateReceived(state *prt.GameState) {
+	r.mu.Lock()
+	r.states = append(r.states, state)
+	r.mu.Unlock()
+	select {
+	case r.frames <- struct{}{}:
+	default:
+	}
+}
+
+func main() {
+	readPath := flag.String("read", "", "capture file written by snakedump -write")
+	join := flag.String("join", "", "join a running game by name as VIEWER and record it")
+	maxFrames := flag.Int("frames", 200, "stop recording a live game after this many states")
+	duration := flag.Duration("duration", 0, "stop recording a live game after this long")
+	group := flag.String("group", "", "multicast group for live recording, e.g. v6")
+	gifPath := flag.String("gif", "", "write an animated GIF to this file")
+	pngDir := flag.String("png", "", "write PNG frames into this directory")
+	from := flag.Int("from", 0, "first state_order to export, 0 for the beginning")
+	to := flag.Int("to", 0, "last state_order to export, 0 for the end")
+	scale := flag.Int("scale", export.DefaultScale, "pixels per field cell")
+	delay := flag.Duration("delay", 0, "delay between frames, defaults to the game's state_delay_ms")
+	width := flag.Int("width", 0, "field width, taken from the game config when known")
+	height := flag.Int("height", 0, "field height, taken from the game config when known")
+	flag.Parse()
+
+	if *gifPath == "" && *pngDir == "" {
+		log.Fatal("Nothing to write: set -gif and/or -png")
+	}
+	var rec *recording
+	var err error
+	switch {
+	case *readPath != "":
+		rec, err = readCapture(*readPath)
+	case *join != "":
+		rec, err = recordLive(*join, *group, *maxFrames, *duration)
+	default:
+		log.Fatal("Set -read or -join")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := export.Options{
+		Width:  int32(*width),
+		Height: int32(*height),
+		Scale:  *scale,
+		Delay:  *delay,
+		From:   int32(*from),
+		To:     int32(*to),
+	}
+	if rec.config != nil {
+		if opts.Width == 0 {
+			opts.Width = rec.config.GetWidth()
+		}
+		if opts.Height == 0 {
+			opts.Height = rec.config.GetHeight()
+		}
+		if opts.Delay == 0 {
+			opts.Delay = time.Duration(rec.config.GetStateDelayMs()) * time.Millisecond
+		}
+	}
+	if opts.Width == 0 || opts.Height == 0 {
+		w, h := bounds(rec.states)
+		opts.Width, opts.Height = max(opts.Width, w), max(opts.Height, h)
+		log.Printf("Field size unknown, using %dx%d from the recorded states", opts.Width, opts.Height)
+	}
+	if rec.encoded {
+		for i, state := range rec.states {
+			rec.states[i] = logic.DecodeState(state, opts.Width, opts.Height)
+		}
+	}
+
+	if *gifPath != "" {
+		if err := export.WriteGIF(*gifPath, rec.states, opts); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Wrote %s\n", *gifPath)
+	}
+	if *pngDir != "" {
+		n, err := export.WritePNGs(*pngDir, rec.states, opts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Wrote %d frames to %s\n", n, *pngDir)
+	}
+}
+
+func readCapture(path string) (*recording, error) {
+	reader, err := capture.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	rec := &recording{encoded: true}
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return rec, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var msg prt.GameMessage
+		if err := proto.Unmarshal(record.Data, &msg); err != nil {
+			continue
+		}
+		switch {
+		case msg.GetState() != nil:
+			rec.states = append(rec.states, msg.GetState().GetState())
+		case msg.GetAnnouncement() != nil && rec.config == nil:
+			if games := msg.GetAnnouncement().GetGames(); len(games) > 0 {
+				rec.config = games[0].GetConfig()
+			}
+		}
+	}
+}
+
+func recordLive(gameName, group string, maxFrames int, duration time.Duration) (*recording, error) {
+	rec := &recording{frames: make(chan struct{}, 1)}
+	mgr := network.NewNetworkManager(prt.NodeRole_NORMAL, nil)
+	mgr.SetMulticastGroup(group)
+	mgr.SetGameStateListener(rec)
+	if err := mgr.Start(); err != nil {
+		return nil, fmt.Errorf("starting network manager: %v", err)
+	}
+	defer mgr.Close()
+
+	var info *network.GameInfo
+	for deadline := time.Now().Add(5 * time.Second); info == nil; {
+		if found, ok := mgr.LookupGame(gameName); ok {
+			info = found
+		} else if time.Now().After(deadline) {
+			return nil, fmt.Errorf("game %q not found", gameName)
+		} else {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	joined, err := mgr.SendJoinRequest(prt.PlayerType_ROBOT, "snakeexport", info, prt.NodeRole_VIEWER)
+	if err != nil {
+		return nil, fmt.Errorf("joining game: %v", err)
+	}
+	select {
+	case <-joined:
+	case <-time.After(5 * time.Second):
+		return nil, errors.New("join timeout: no response from game master")
+	}
+	rec.config = info.Announcement.GetConfig()
+	mgr.SetGameAnnouncement(info.Announcement)
+	mgr.SetActivityManager(rec.config.GetStateDelayMs())
+	log.Printf("Recording '%s', press Ctrl+C to stop", gameName)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	var timeout <-chan time.Time
+	if duration > 0 {
+		timeout = time.After(duration)
+	}
+	for {
+		select {
+		case <-rec.frames:
+			rec.mu.Lock()
+			done := maxFrames > 0 && len(rec.states) >= maxFrames
+			rec.mu.Unlock()
+			if done {
+				return rec, nil
+			}
+		case <-timeout:
+			return rec, nil
+		case <-interrupt:
+			return rec, nil
+		}
+	}
+}
+
+func bounds(states []*prt.GameState) (int32, int32) {
+	var width, height int32
+	grow := func(coord *prt.GameState_Coord) {
+		width = max(width, coord.GetX()+1)
+		height = max(height, coord.GetY()+1)
+	}
+	for _, state := range states {
+		for _, food := range state.GetFoods() {
+			grow(food)
+		}
+		for _, snake := range state.GetSnakes() {
+			for _, point := range snake.GetPoints() {
+				grow(point)
+			}
+		}
+	}
+	return width, height
+}
@@ -0,0 +1,26 @@
+// Command snaketerm is the game's -ui terminal without the window front-end.
+// It does not link ebiten, so it builds and runs on machines without a
+// graphics stack.
+package main
+
+import (
+	"flag"
+	"os"
+	"snake-game/internal/game/core"
+	"snake-game/internal/game/terminal"
+)
+
+func main() {
+	connect := flag.String("connect", "", "ask the master at host:port for its games instead of waiting for multicast")
+	group := flag.String("group", os.Getenv("MULTICAST_GROUP"), "multicast group, e.g. v6")
+	iface := flag.String("iface", os.Getenv("MULTICAST_IFACE"), "network interface for the multicast group")
+	listen := flag.String("listen", os.Getenv("UNICAST_ADDR"), "bind the game socket here, e.g. :9192, so players can connect directly")
+	flag.Parse()
+
+	game := core.NewGame(terminal.NewFrontend())
+	game.SetMulticastGroup(*group)
+	game.SetMulticastInterface(*iface)
+	game.SetListenAddr(*listen)
+	game.SetConnectAddr(*connect)
+	game.Start()
+}
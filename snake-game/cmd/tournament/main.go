@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"snake-game/internal/game/bots"
+	"snake-game/internal/tournament"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func main() {
+	botList := flag.String("bots", strings.Join(bots.Names(), ","), "comma-separated bot strategies, each seated once per game")
+	games := flag.Int("games", 1000, "number of games to simulate")
+	fields := flag.String("fields", "40x30", "comma-separated field sizes, e.g. 20x15,40x30")
+	foods := flag.String("food", "1", "comma-separated food_static settings")
+	maxTicks := flag.Int("ticks", 2000, "end a game after this many ticks")
+	workers := flag.Int("workers", runtime.NumCPU(), "games simulated in parallel")
+	seed := flag.Uint64("seed", uint64(time.Now().UnixNano()), "seed for snake placement, food and bot randomness")
+	csvPath := flag.String("csv", "", "write per-bot statistics as CSV to this file")
+	jsonPath := flag.String("json", "", "write the full report as JSON to this file")
+	flag.Parse()
+
+	opts := tournament.Options{
+		Bots:     strings.Split(*botList, ","),
+		Games:    *games,
+		MaxTicks: *maxTicks,
+		Workers:  *workers,
+		Seed:     *seed,
+	}
+	var err error
+	if opts.Fields, err = parseFields(*fields); err != nil {
+		log.Fatal(err)
+	}
+	if opts.Foods, err = parseFoods(*foods); err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := tournament.Run(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("seed %d\n", *seed)
+	report.WriteText(os.Stdout)
+	if *csvPath != "" {
+		if err := writeFile(*csvPath, report.WriteCSV); err != nil {
+			log.Fatalf("Failed to write CSV: %v", err)
+		}
+	}
+	if *jsonPath != "" {
+		if err := writeFile(*jsonPath, report.WriteJSON); err != nil {
+			log.Fatalf("Failed to write JSON: %v", err)
+		}
+	}
+}
+
+func parseFields(s string) ([]tournament.Field, error) {
+	var fields []tournament.Field
+	for _, part := range strings.Split(s, ",") {
+		w, h, ok := strings.Cut(strings.TrimSpace(part), "x")
+		width, errW := strconv.Atoi(w)
+		height, errH := strconv.Atoi(h)
+		if !ok || errW != nil || errH != nil || width < 10 || width > 100 || height < 10 || height > 100 {
+			return nil, fmt.Errorf("invalid field size %q, want WxH between 10 and 100", part)
+		}
+		fields = append(fields, tournament.Field{Width: int32(width), Height: int32(height)})
+	}
+	return fields, nil
+}
+
+func parseFoods(s string) ([]int32, error) {
+	var foods []int32
+	for _, part := range strings.Split(s, ",") {
+		food, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || food < 0 || food > 100 {
+			return nil, fmt.Errorf("invalid food setting %q, want 0 to 100", part)
+		}
+		foods = append(foods, int32(food))
+	}
+	return foods, nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
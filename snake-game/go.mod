@@ -9,7 +9,9 @@ require (
 	github.com/hajimehoshi/ebiten/v2 v2.8.8
 	github.com/ilyakaznacheev/cleanenv v1.5.0
 	golang.org/x/image v0.20.0
+	golang.org/x/term v0.24.0
 	google.golang.org/protobuf v1.36.9
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -21,6 +23,5 @@ require (
 	github.com/joho/godotenv v1.5.1 // indirect
 	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 	olympos.io/encoding/edn v0.0.0-20201019073823-d3554ca0b0a3 // indirect
 )
@@ -0,0 +1,276 @@
+// Package botapi serves a local JSON-over-HTTP API that lets programs in any
+// language play through a running client:
+//
+//	GET  /me      {"player_id": 123, "role": "NORMAL", "game_name": "lab"}
+//	GET  /state   the latest GameState
+//	GET  /stream  every new GameState, one JSON object per line
+//	POST /steer   {"direction": "LEFT"}, sent as application/json
+//
+// States use the protobuf JSON mapping, so field names are camelCase and
+// enums are their names. Whoever reaches the API steers our snake, so it
+// only listens on loopback unless told otherwise, and turns away browsers:
+// requests with a foreign Origin or, on loopback, a foreign Host.
+package botapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	proto "snake-game/internal/proto/gen"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultAddr  = "127.0.0.1:8765"
+	clientBuffer = 8
+	maxSteerBody = 1024
+)
+
+// Player is the running client behind the API.
+type Player interface {
+	// PlayerID reports this node's player, or false outside a game.
+	PlayerID() (int32, bool)
+	Role() proto.NodeRole
+	// Steer turns our snake the same way a key press would.
+	Steer(direction proto.Direction) error
+}
+
+type identity struct {
+	PlayerID int32  `json:"player_id"`
+	Role     string `json:"role"`
+	GameName string `json:"game_name,omitempty"`
+	InGame   bool   `json:"in_game"`
+}
+
+type steerRequest struct {
+	Direction string `json:"direction"`
+}
+
+type Server struct {
+	mu       sync.Mutex
+	player   Player
+	gameName string
+	last     []byte
+	clients  map[chan []byte]struct{}
+	listener net.Listener
+	srv      *http.Server
+	public   bool
+}
+
+func NewServer(player Player) *Server {
+	s := &Server{player: player, clients: make(map[chan []byte]struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/me", s.handleMe)
+	mux.HandleFunc("/state", s.handleState)
+	mux.HandleFunc("/stream", s.handleStream)
+	mux.HandleFunc("/steer", s.handleSteer)
+	s.srv = &http.Server{Handler: s.guard(mux)}
+	return s
+}
+
+// guard turns away browsers. A page on any site may POST to a local port,
+// and one whose name rebinds to 127.0.0.1 may read the answers as well;
+// the first sends its Origin and the second a Host that is not ours. Bots
+// send no Origin and reach us as localhost.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && !loopbackOrigin(origin) {
+			http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+			return
+		}
+		if !s.public && !loopbackHost(r.Host) {
+			http.Error(w, fmt.Sprintf("host %q is not loopback", r.Host), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func loopbackOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && loopbackHost(u.Host)
+}
+
+func loopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// SetGame starts a new game: the previous game's last state is dropped.
+func (s *Server) SetGame(gameName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gameName = gameName
+	s.last = nil
+}
+
+// Start serves the API on addr. Unless public is set, addr must resolve to
+// a loopback address, so ":8765" is refused rather than open to the LAN.
+func (s *Server) Start(addr string, public bool) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("starting bot API: %v", err)
+	}
+	if tcp, ok := listener.Addr().(*net.TCPAddr); !public && (!ok || !tcp.IP.IsLoopback()) {
+		listener.Close()
+		return fmt.Errorf("bot API address %s is not loopback", addr)
+	}
+	s.listener = listener
+	s.public = public
+	go func() {
+		if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Bot API stopped: %v", err)
+		}
+	}()
+	log.Printf("Bot API available at http://%s/", listener.Addr())
+	return nil
+}
+
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+func (s *Server) Close() error {
+	s.mu.Lock()
+	for client := range s.clients {
+		close(client)
+		delete(s.clients, client)
+	}
+	s.mu.Unlock()
+	return s.srv.Close()
+}
+
+func (s *Server) OnGameStateReceived(state *proto.GameState) {
+	data, err := protojson.Marshal(state)
+	if err != nil {
+		log.Printf("Error encoding state for bots: %v", err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = data
+	for client := range s.clients {
+		select {
+		case client <- data:
+		default:
+		}
+	}
+}
+
+func (s *Server) subscribe() (chan []byte, []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client := make(chan []byte, clientBuffer)
+	s.clients[client] = struct{}{}
+	return client, s.last
+}
+
+func (s *Server) unsubscribe(client chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[client]; ok {
+		delete(s.clients, client)
+		close(client)
+	}
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	id, inGame := s.player.PlayerID()
+	s.mu.Lock()
+	gameName := s.gameName
+	s.mu.Unlock()
+	me := identity{PlayerID: id, Role: s.player.Role().String(), InGame: inGame}
+	if inGame {
+		me.GameName = gameName
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(me)
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	last := s.last
+	s.mu.Unlock()
+	if last == nil {
+		http.Error(w, "no state yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(last)
+}
+
+// handleStream writes each state on its own line as it arrives, so a bot
+// can read the response line by line. A slow reader misses states rather
+// than holding up the game.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	client, last := s.subscribe()
+	defer s.unsubscribe(client)
+	if last != nil {
+		fmt.Fprintf(w, "%s\n", last)
+	}
+	flusher.Flush()
+	for {
+		select {
+		case data, ok := <-client:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "%s\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) handleSteer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, "send the steer request as application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	var req steerRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSteerBody)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("bad steer request: %v", err), http.StatusBadRequest)
+		return
+	}
+	value, ok := proto.Direction_value[strings.ToUpper(req.Direction)]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown direction %q, want UP, DOWN, LEFT or RIGHT", req.Direction), http.StatusBadRequest)
+		return
+	}
+	if err := s.player.Steer(proto.Direction(value)); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
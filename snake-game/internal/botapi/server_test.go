@@ -0,0 +1,94 @@
+package botapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	proto "snake-game/internal/proto/gen"
+	"strings"
+	"testing"
+)
+
+func TestStartKeepsToLoopback(t *testing.T) {
+	tests := []struct {
+		addr    string
+		public  bool
+		allowed bool
+	}{
+		{"127.0.0.1:0", false, true},
+		{"localhost:0", false, true},
+		{":0", false, false},
+		{"0.0.0.0:0", false, false},
+		{":0", true, true},
+	}
+	for _, tt := range tests {
+		s := NewServer(nil)
+		err := s.Start(tt.addr, tt.public)
+		if err == nil {
+			s.Close()
+		}
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("Start(%q, public=%v) allowed = %v, want %v (%v)", tt.addr, tt.public, allowed, tt.allowed, err)
+		}
+	}
+}
+
+type steerRecorder struct{ steered []proto.Direction }
+
+func (p *steerRecorder) PlayerID() (int32, bool) { return 1, true }
+func (p *steerRecorder) Role() proto.NodeRole    { return proto.NodeRole_NORMAL }
+func (p *steerRecorder) Steer(direction proto.Direction) error {
+	p.steered = append(p.steered, direction)
+	return nil
+}
+
+func TestSteerTurnsAwayBrowsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        string
+		origin      string
+		contentType string
+		want        int
+	}{
+		{"bot", "127.0.0.1:8765", "", "application/json", http.StatusNoContent},
+		{"localhost with charset", "localhost:8765", "", "application/json; charset=utf-8", http.StatusNoContent},
+		{"loopback page", "127.0.0.1:8765", "http://localhost:3000", "application/json", http.StatusNoContent},
+		{"form post", "127.0.0.1:8765", "", "text/plain", http.StatusUnsupportedMediaType},
+		{"no content type", "127.0.0.1:8765", "", "", http.StatusUnsupportedMediaType},
+		{"foreign page", "127.0.0.1:8765", "https://example.com", "application/json", http.StatusForbidden},
+		{"rebound name", "evil.example:8765", "", "application/json", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := &steerRecorder{}
+			s := NewServer(player)
+			r := httptest.NewRequest(http.MethodPost, "/steer", strings.NewReader(`{"direction": "LEFT"}`))
+			r.Host = tt.host
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			s.srv.Handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if steered := len(player.steered) > 0; steered != (tt.want == http.StatusNoContent) {
+				t.Errorf("steered = %v with status %d", player.steered, w.Code)
+			}
+		})
+	}
+}
+
+func TestPublicServerTakesAnyHost(t *testing.T) {
+	s := NewServer(&steerRecorder{})
+	s.public = true
+	r := httptest.NewRequest(http.MethodGet, "/me", nil)
+	r.Host = "snakes.lan:8765"
+	w := httptest.NewRecorder()
+	s.srv.Handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
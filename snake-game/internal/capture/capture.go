@@ -0,0 +1,85 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	SourceUnicast   = "unicast"
+	SourceMulticast = "multicast"
+)
+
+type Record struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"`
+	From   string    `json:"from"`
+	Data   []byte    `json:"data"`
+}
+
+type Writer struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func Create(path string) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating capture file: %v", err)
+	}
+	return &Writer{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+func (w *Writer) Write(record *Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(record); err != nil {
+		return fmt.Errorf("writing capture record: %v", err)
+	}
+	return nil
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+type Reader struct {
+	file    *os.File
+	scanner *bufio.Scanner
+}
+
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening capture file: %v", err)
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	return &Reader{file: file, scanner: scanner}, nil
+}
+
+func (r *Reader) Next() (*Record, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading capture file: %v", err)
+		}
+		return nil, io.EOF
+	}
+	var record Record
+	if err := json.Unmarshal(r.scanner.Bytes(), &record); err != nil {
+		return nil, fmt.Errorf("decoding capture record: %v", err)
+	}
+	return &record, nil
+}
+
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
@@ -0,0 +1,328 @@
+package conformance
+
+import (
+	"net"
+	prt "snake-game/internal/proto/gen"
+	"time"
+)
+
+const replyTimeout = 1 * time.Second
+
+// resendSlack is how much later than its interval a periodic message may
+// come: a sender that checks on a ticker of that period can be up to one
+// period late, and the scheduler adds a little more.
+func resendSlack(interval time.Duration) time.Duration {
+	return interval + 20*time.Millisecond
+}
+
+type masterSuite struct {
+	peer       *Peer
+	target     *net.UDPAddr
+	report     *Report
+	game       *prt.GameAnnouncement
+	stateDelay time.Duration
+	playerID   int32
+}
+
+func CheckMaster(target *net.UDPAddr) (*Report, error) {
+	peer, err := NewPeer()
+	if err != nil {
+		return nil, err
+	}
+	defer peer.Close()
+	s := &masterSuite{
+		peer:   peer,
+		target: target,
+		report: &Report{Target: target.String(), Mode: "fake NORMAL against MASTER"},
+	}
+	steps := []func() bool{
+		s.checkDiscover,
+		s.checkInvalidJoin,
+		s.checkJoin,
+		s.checkStates,
+		s.checkPing,
+		s.checkSteer,
+		s.checkRetransmit,
+		s.checkIdlePings,
+		s.checkLeave,
+	}
+	for _, step := range steps {
+		if !step() {
+			s.report.add("remaining-rules", Skip, "aborted after a failed prerequisite")
+			break
+		}
+	}
+	return s.report, nil
+}
+
+func (s *masterSuite) checkDiscover() bool {
+	sentAt := time.Now()
+	if _, err := s.peer.Send(&prt.GameMessage{Type: &prt.GameMessage_Discover{Discover: &prt.GameMessage_DiscoverMsg{}}}, s.target); err != nil {
+		s.report.add("discover-answered", Fail, "sending discover: %v", err)
+		return false
+	}
+	received, err := s.peer.Expect(replyTimeout, func(r *Received) bool { return r.Msg.GetAnnouncement() != nil })
+	if err != nil {
+		s.report.add("discover-answered", Fail, "%v", err)
+		return false
+	}
+	games := received.Msg.GetAnnouncement().GetGames()
+	if len(games) == 0 {
+		s.report.add("discover-answered", Fail, "announcement without games")
+		return false
+	}
+	s.game = games[0]
+	s.stateDelay = time.Duration(s.game.GetConfig().GetStateDelayMs()) * time.Millisecond
+	s.report.add("discover-answered", Pass, "game %q announced after %s", s.game.GetGameName(), received.At.Sub(sentAt).Round(time.Microsecond))
+	return true
+}
+
+func (s *masterSuite) joinMsg(role prt.NodeRole) *prt.GameMessage {
+	return &prt.GameMessage{Type: &prt.GameMessage_Join{Join: &prt.GameMessage_JoinMsg{
+		PlayerType:    prt.PlayerType_ROBOT,
+		PlayerName:    "conformance",
+		GameName:      s.game.GetGameName(),
+		RequestedRole: role,
+	}}}
+}
+
+func (s *masterSuite) checkInvalidJoin() bool {
+	other, err := NewPeer()
+	if err != nil {
+		s.report.add("join-invalid-role-rejected", Skip, "%v", err)
+		return true
+	}
+	defer other.Close()
+	seq, err := other.Send(s.joinMsg(prt.NodeRole_MASTER), s.target)
+	if err != nil {
+		s.report.add("join-invalid-role-rejected", Skip, "%v", err)
+		return true
+	}
+	received, err := other.Expect(replyTimeout, func(r *Received) bool {
+		return r.Msg.GetError() != nil || (r.Msg.GetAck() != nil && r.Msg.GetMsgSeq() == seq)
+	})
+	switch {
+	case err != nil:
+		s.report.add("join-invalid-role-rejected", Pass, "join with role MASTER was ignored")
+	case received.Msg.GetError() != nil:
+		s.report.add("join-invalid-role-rejected", Pass, "error: %q", received.Msg.GetError().GetErrorMessage())
+	default:
+		s.report.add("join-invalid-role-rejected", Fail, "join with role MASTER was accepted as player %d", received.Msg.GetReceiverId())
+	}
+	return true
+}
+
+func (s *masterSuite) checkJoin() bool {
+	seq, err := s.peer.Send(s.joinMsg(prt.NodeRole_NORMAL), s.target)
+	if err != nil {
+		s.report.add("join-acked", Fail, "sending join: %v", err)
+		return false
+	}
+	received, err := s.peer.Expect(replyTimeout, func(r *Received) bool {
+		return r.Msg.GetError() != nil || (r.Msg.GetAck() != nil && r.Msg.GetMsgSeq() == seq)
+	})
+	if err != nil {
+		s.report.add("join-acked", Fail, "%v", err)
+		return false
+	}
+	if received.Msg.GetError() != nil {
+		s.report.add("join-acked", Fail, "join refused: %q", received.Msg.GetError().GetErrorMessage())
+		return false
+	}
+	if received.Msg.GetReceiverId() == 0 {
+		s.report.add("join-acked", Fail, "ack has no receiver_id")
+		return false
+	}
+	s.playerID = received.Msg.GetReceiverId()
+	s.peer.SetPlayerID(s.playerID)
+	s.report.add("join-acked", Pass, "assigned player id %d", s.playerID)
+	return true
+}
+
+func (s *masterSuite) checkStates() bool {
+	if _, err := s.peer.Expect(2*s.stateDelay+replyTimeout, isState); err != nil {
+		s.report.add("state-received", Fail, "%v", err)
+		return false
+	}
+	s.report.add("state-received", Pass, "")
+	states := s.peer.Collect(5*s.stateDelay, isState)
+	if len(states) < 2 {
+		s.report.add("state-order-monotonic", Fail, "only %d states in %s", len(states), 5*s.stateDelay)
+		return false
+	}
+	monotonic := true
+	seenSelf := false
+	var intervals []time.Duration
+	for i, received := range states {
+		state := received.Msg.GetState().GetState()
+		for _, player := range state.GetPlayers().GetPlayers() {
+			if player.GetId() == s.playerID {
+				seenSelf = true
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		prev := states[i-1].Msg.GetState().GetState()
+		if state.GetStateOrder() < prev.GetStateOrder() {
+			monotonic = false
+		}
+		if state.GetStateOrder() > prev.GetStateOrder() {
+			intervals = append(intervals, received.At.Sub(states[i-1].At))
+		}
+	}
+	if monotonic {
+		s.report.add("state-order-monotonic", Pass, "%d states", len(states))
+	} else {
+		s.report.add("state-order-monotonic", Fail, "state_order decreased")
+	}
+	if seenSelf {
+		s.report.add("state-lists-new-player", Pass, "")
+	} else {
+		s.report.add("state-lists-new-player", Fail, "player %d missing from GameState.players", s.playerID)
+	}
+	if len(intervals) == 0 {
+		s.report.add("state-delay-respected", Skip, "no distinct states observed")
+		return true
+	}
+	var total time.Duration
+	for _, interval := range intervals {
+		total += interval
+	}
+	average := total / time.Duration(len(intervals))
+	if average < s.stateDelay/2 || average > s.stateDelay*3/2 {
+		s.report.add("state-delay-respected", Fail, "average interval %s, config says %s", average.Round(time.Millisecond), s.stateDelay)
+	} else {
+		s.report.add("state-delay-respected", Pass, "average interval %s", average.Round(time.Millisecond))
+	}
+	return true
+}
+
+func (s *masterSuite) checkPing() bool {
+	s.expectAck("ping-acked", &prt.GameMessage{Type: &prt.GameMessage_Ping{Ping: &prt.GameMessage_PingMsg{}}})
+	return true
+}
+
+func (s *masterSuite) expectAck(rule string, msg *prt.GameMessage) bool {
+	seq, err := s.peer.Send(msg, s.target)
+	if err != nil {
+		s.report.add(rule, Fail, "%v", err)
+		return false
+	}
+	received, err := s.peer.Expect(s.stateDelay, isAckFor(seq))
+	if err != nil {
+		s.report.add(rule, Fail, "%v", err)
+		return false
+	}
+	if received.Msg.GetReceiverId() != 0 && received.Msg.GetReceiverId() != s.playerID {
+		s.report.add(rule, Fail, "ack addressed to player %d", received.Msg.GetReceiverId())
+		return false
+	}
+	s.report.add(rule, Pass, "")
+	return true
+}
+
+func (s *masterSuite) ownSnake(state *prt.GameState) *prt.GameState_Snake {
+	for _, snake := range state.GetSnakes() {
+		if snake.GetPlayerId() == s.playerID {
+			return snake
+		}
+	}
+	return nil
+}
+
+func (s *masterSuite) checkSteer() bool {
+	latest, err := s.peer.Expect(2*s.stateDelay, isState)
+	if err != nil {
+		s.report.add("steer-applied", Skip, "no state to steer from")
+		return true
+	}
+	snake := s.ownSnake(latest.Msg.GetState().GetState())
+	if snake == nil {
+		s.report.add("steer-applied", Fail, "no snake for player %d", s.playerID)
+		return true
+	}
+	turn := prt.Direction_LEFT
+	if snake.GetHeadDirection() == prt.Direction_LEFT || snake.GetHeadDirection() == prt.Direction_RIGHT {
+		turn = prt.Direction_UP
+	}
+	if !s.expectAck("steer-acked", &prt.GameMessage{Type: &prt.GameMessage_Steer{Steer: &prt.GameMessage_SteerMsg{Direction: turn}}}) {
+		return true
+	}
+	_, err = s.peer.Expect(3*s.stateDelay, func(r *Received) bool {
+		snake := s.ownSnake(r.Msg.GetState().GetState())
+		return snake != nil && snake.GetHeadDirection() == turn
+	})
+	if err != nil {
+		s.report.add("steer-applied", Fail, "head did not turn %s within 3 states", turn)
+	} else {
+		s.report.add("steer-applied", Pass, "head turned %s", turn)
+	}
+	return true
+}
+
+func (s *masterSuite) checkRetransmit() bool {
+	s.peer.SetAutoAck(false)
+	s.peer.Drain()
+	window := s.stateDelay * 7 / 10
+	received := s.peer.Collect(window, func(r *Received) bool { return needsAck(r.Msg) })
+	s.peer.SetAutoAck(true)
+	expected := s.stateDelay / 10
+	firstSeen := make(map[int64]time.Time)
+	for _, r := range received {
+		first, ok := firstSeen[r.Msg.GetMsgSeq()]
+		if !ok {
+			firstSeen[r.Msg.GetMsgSeq()] = r.At
+			continue
+		}
+		gap := r.At.Sub(first)
+		if gap < expected/2 || gap > expected+resendSlack(expected) {
+			s.report.add("unacked-retransmitted", Fail, "msg_seq %d resent after %s, expected about %s", r.Msg.GetMsgSeq(), gap.Round(time.Millisecond), expected)
+		} else {
+			s.report.add("unacked-retransmitted", Pass, "msg_seq %d resent after %s", r.Msg.GetMsgSeq(), gap.Round(time.Millisecond))
+		}
+		return true
+	}
+	if len(received) == 0 {
+		s.report.add("unacked-retransmitted", Fail, "no messages within %s", window)
+	} else {
+		s.report.add("unacked-retransmitted", Fail, "%d messages, none retransmitted within %s", len(received), window)
+	}
+	return true
+}
+
+func (s *masterSuite) checkIdlePings() bool {
+	s.peer.Drain()
+	received := s.peer.Collect(3*s.stateDelay, func(r *Received) bool { return true })
+	maxGap := time.Duration(0)
+	for i := 1; i < len(received); i++ {
+		if gap := received[i].At.Sub(received[i-1].At); gap > maxGap {
+			maxGap = gap
+		}
+	}
+	limit := s.stateDelay/10 + resendSlack(s.stateDelay/10)
+	if len(received) > 1 && maxGap <= limit {
+		s.report.add("pings-when-idle", Pass, "longest silence %s", maxGap.Round(time.Millisecond))
+	} else {
+		s.report.add("pings-when-idle", Fail, "longest silence %s, expected at most %s", maxGap.Round(time.Millisecond), limit)
+	}
+	return true
+}
+
+func (s *masterSuite) checkLeave() bool {
+	leave := &prt.GameMessage{Type: &prt.GameMessage_RoleChange{RoleChange: &prt.GameMessage_RoleChangeMsg{
+		SenderRole:   prt.NodeRole_VIEWER,
+		ReceiverRole: prt.NodeRole_MASTER,
+	}}}
+	s.expectAck("leave-acked", leave)
+	_, err := s.peer.Expect(3*s.stateDelay, func(r *Received) bool {
+		snake := s.ownSnake(r.Msg.GetState().GetState())
+		return snake != nil && snake.GetState() == prt.GameState_Snake_ZOMBIE
+	})
+	if err != nil {
+		s.report.add("leave-zombifies-snake", Fail, "snake still ALIVE after leaving")
+	} else {
+		s.report.add("leave-zombifies-snake", Pass, "")
+	}
+	return true
+}
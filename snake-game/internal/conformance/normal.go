@@ -0,0 +1,252 @@
+package conformance
+
+import (
+	"net"
+	prt "snake-game/internal/proto/gen"
+	"time"
+)
+
+const (
+	fakeGameName   = "conformance"
+	fakePlayerID   = 2
+	fakeMasterID   = 1
+	fakeStateDelay = 200 * time.Millisecond
+	announceEvery  = 1 * time.Second
+	// takeOverTimeout leaves the deputy a few state delays to notice the
+	// master is gone.
+	takeOverTimeout = 10 * fakeStateDelay
+)
+
+type NormalSuite struct {
+	peer       *Peer
+	group      *net.UDPAddr
+	target     *net.UDPAddr
+	report     *Report
+	config     *prt.GameConfig
+	stateOrder int32
+	players    *prt.GamePlayers
+}
+
+func NewNormalSuite(group *net.UDPAddr, target *net.UDPAddr) (*NormalSuite, error) {
+	peer, err := NewPeer()
+	if err != nil {
+		return nil, err
+	}
+	peer.SetPlayerID(fakeMasterID)
+	name := "multicast " + group.String()
+	if target != nil {
+		name = target.String()
+	}
+	return &NormalSuite{
+		peer:   peer,
+		group:  group,
+		target: target,
+		report: &Report{Target: name, Mode: "fake MASTER against NORMAL"},
+		config: &prt.GameConfig{Width: 20, Height: 20, FoodStatic: 1, StateDelayMs: int32(fakeStateDelay / time.Millisecond)},
+		players: &prt.GamePlayers{Players: []*prt.GamePlayer{
+			{Name: "conformance-master", Id: fakeMasterID, Role: prt.NodeRole_MASTER, Type: prt.PlayerType_ROBOT},
+		}},
+	}, nil
+}
+
+func (s *NormalSuite) MasterAddr() *net.UDPAddr {
+	return s.peer.LocalAddr()
+}
+
+func (s *NormalSuite) Run(joinTimeout time.Duration) *Report {
+	defer s.peer.Close()
+	client := s.waitForJoin(joinTimeout)
+	if client == nil {
+		s.report.add("remaining-rules", Skip, "no client joined")
+		return s.report
+	}
+	s.target = client
+	s.checkStateAcked()
+	s.checkStaleStateAcked()
+	s.checkIdlePings()
+	s.checkSteers()
+	s.checkRoleChangeAcked()
+	return s.report
+}
+
+func (s *NormalSuite) announcement() *prt.GameMessage {
+	return &prt.GameMessage{Type: &prt.GameMessage_Announcement{Announcement: &prt.GameMessage_AnnouncementMsg{
+		Games: []*prt.GameAnnouncement{{
+			Players:  s.players,
+			Config:   s.config,
+			CanJoin:  true,
+			GameName: fakeGameName,
+		}},
+	}}}
+}
+
+func (s *NormalSuite) waitForJoin(timeout time.Duration) *net.UDPAddr {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		s.peer.Send(s.announcement(), s.group)
+		if s.target != nil {
+			s.peer.Send(s.announcement(), s.target)
+		}
+		received, err := s.peer.Expect(announceEvery, func(r *Received) bool { return r.Msg.GetJoin() != nil })
+		if err != nil {
+			continue
+		}
+		join := received.Msg.GetJoin()
+		role := join.GetRequestedRole()
+		if join.GetPlayerName() == "" || (role != prt.NodeRole_NORMAL && role != prt.NodeRole_VIEWER) {
+			s.report.add("join-well-formed", Fail, "name %q, role %s", join.GetPlayerName(), role)
+		} else {
+			s.report.add("join-well-formed", Pass, "%q joins as %s", join.GetPlayerName(), role)
+		}
+		if join.GetGameName() != fakeGameName {
+			s.report.add("join-names-game", Fail, "game_name %q", join.GetGameName())
+		} else {
+			s.report.add("join-names-game", Pass, "")
+		}
+		s.players.Players = append(s.players.Players, &prt.GamePlayer{
+			Name: join.GetPlayerName(), Id: fakePlayerID, Role: role, Type: join.GetPlayerType(),
+			IpAddress: received.From.IP.String(), Port: int32(received.From.Port),
+		})
+		s.peer.Ack(&prt.GameMessage{MsgSeq: received.Msg.GetMsgSeq(), SenderId: fakePlayerID}, received.From)
+		return received.From
+	}
+	s.report.add("join-well-formed", Fail, "no JoinMsg within %s", timeout)
+	return nil
+}
+
+func (s *NormalSuite) sendState(order int32) (int64, error) {
+	state := &prt.GameState{
+		StateOrder: order,
+		Players:    s.players,
+		Foods:      []*prt.GameState_Coord{{X: 10, Y: 10}},
+		Snakes: []*prt.GameState_Snake{{
+			PlayerId: fakePlayerID,
+			// The head, then the offset to the tail one cell below it.
+			Points:        []*prt.GameState_Coord{{X: 5, Y: 5}, {X: 0, Y: 1}},
+			HeadDirection: prt.Direction_UP,
+		}},
+	}
+	return s.peer.Send(&prt.GameMessage{
+		ReceiverId: fakePlayerID,
+		Type:       &prt.GameMessage_State{State: &prt.GameMessage_StateMsg{State: state}},
+	}, s.target)
+}
+
+func (s *NormalSuite) checkStateAcked() {
+	acked := 0
+	const rounds = 5
+	for i := 0; i < rounds; i++ {
+		s.stateOrder++
+		seq, err := s.sendState(s.stateOrder)
+		if err != nil {
+			continue
+		}
+		if _, err := s.peer.Expect(fakeStateDelay, isAckFor(seq)); err == nil {
+			acked++
+		}
+	}
+	if acked == rounds {
+		s.report.add("state-acked", Pass, "%d/%d states acked", acked, rounds)
+	} else {
+		s.report.add("state-acked", Fail, "%d/%d states acked", acked, rounds)
+	}
+}
+
+func (s *NormalSuite) checkStaleStateAcked() {
+	seq, err := s.sendState(s.stateOrder - 1)
+	if err != nil {
+		s.report.add("stale-state-acked", Skip, "%v", err)
+		return
+	}
+	if _, err := s.peer.Expect(fakeStateDelay, isAckFor(seq)); err != nil {
+		s.report.add("stale-state-acked", Fail, "state with old state_order not acked")
+	} else {
+		s.report.add("stale-state-acked", Pass, "")
+	}
+}
+
+func (s *NormalSuite) checkIdlePings() {
+	s.peer.Drain()
+	received := s.peer.Collect(3*fakeStateDelay, func(r *Received) bool { return true })
+	maxGap := time.Duration(0)
+	for i := 1; i < len(received); i++ {
+		if gap := received[i].At.Sub(received[i-1].At); gap > maxGap {
+			maxGap = gap
+		}
+	}
+	limit := fakeStateDelay/10 + resendSlack(fakeStateDelay/10)
+	if len(received) > 1 && maxGap <= limit {
+		s.report.add("pings-when-idle", Pass, "%d %ss, longest silence %s", len(received), messageKind(received[0].Msg), maxGap.Round(time.Millisecond))
+	} else {
+		s.report.add("pings-when-idle", Fail, "%d messages, longest silence %s, expected at most %s", len(received), maxGap.Round(time.Millisecond), limit)
+	}
+}
+
+func (s *NormalSuite) checkSteers() {
+	steers := s.peer.Collect(3*fakeStateDelay, func(r *Received) bool { return r.Msg.GetSteer() != nil })
+	if len(steers) == 0 {
+		s.report.add("steer-has-sender", Skip, "client did not steer")
+		return
+	}
+	for _, steer := range steers {
+		if steer.Msg.GetSenderId() != fakePlayerID {
+			s.report.add("steer-has-sender", Fail, "sender_id %d, expected %d", steer.Msg.GetSenderId(), fakePlayerID)
+			return
+		}
+	}
+	s.report.add("steer-has-sender", Pass, "%d steers", len(steers))
+}
+
+func (s *NormalSuite) checkRoleChangeAcked() {
+	seq, err := s.peer.Send(&prt.GameMessage{
+		ReceiverId: fakePlayerID,
+		Type: &prt.GameMessage_RoleChange{RoleChange: &prt.GameMessage_RoleChangeMsg{
+			SenderRole:   prt.NodeRole_MASTER,
+			ReceiverRole: prt.NodeRole_DEPUTY,
+		}},
+	}, s.target)
+	if err != nil {
+		s.report.add("role-change-acked", Skip, "%v", err)
+		return
+	}
+	if _, err := s.peer.Expect(fakeStateDelay, isAckFor(seq)); err != nil {
+		s.report.add("role-change-acked", Fail, "%v", err)
+		return
+	}
+	s.report.add("role-change-acked", Pass, "")
+	s.checkDeputyTakesOver()
+}
+
+// checkDeputyTakesOver makes the fake master fall silent after naming the
+// client DEPUTY. A deputy that notices takes over as MASTER, which shows in
+// it starting to answer discovery.
+func (s *NormalSuite) checkDeputyTakesOver() {
+	s.peer.SetAutoAck(false)
+	defer s.peer.SetAutoAck(true)
+	discover := &prt.GameMessage{Type: &prt.GameMessage_Discover{Discover: &prt.GameMessage_DiscoverMsg{}}}
+	silentSince := time.Now()
+	for time.Since(silentSince) < takeOverTimeout {
+		if _, err := s.peer.Send(discover, s.target); err != nil {
+			s.report.add("deputy-takes-over", Skip, "%v", err)
+			return
+		}
+		received, err := s.peer.Expect(fakeStateDelay, func(r *Received) bool { return r.Msg.GetAnnouncement() != nil })
+		if err == nil {
+			s.report.add("deputy-takes-over", Pass, "answered discovery after %s of silence", received.At.Sub(silentSince).Round(time.Millisecond))
+			return
+		}
+	}
+	s.report.add("deputy-takes-over", Fail, "not answering discovery as MASTER after %s of silence", takeOverTimeout)
+}
+
+func messageKind(msg *prt.GameMessage) string {
+	switch {
+	case msg.GetPing() != nil:
+		return "ping"
+	case msg.GetAck() != nil:
+		return "ack"
+	case msg.GetSteer() != nil:
+		return "steer"
+	}
+	return "message"
+}
@@ -0,0 +1,183 @@
+package conformance
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"net"
+	prt "snake-game/internal/proto/gen"
+	"sync"
+	"time"
+)
+
+type Received struct {
+	Msg  *prt.GameMessage
+	From *net.UDPAddr
+	At   time.Time
+}
+
+type Peer struct {
+	conn     *net.UDPConn
+	mu       sync.Mutex
+	msgSeq   int64
+	autoAck  bool
+	playerID int32
+	inbox    chan *Received
+	done     chan struct{}
+}
+
+func NewPeer() (*Peer, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{})
+	if err != nil {
+		return nil, fmt.Errorf("binding peer socket: %v", err)
+	}
+	p := &Peer{
+		conn:    conn,
+		msgSeq:  1,
+		autoAck: true,
+		inbox:   make(chan *Received, 256),
+		done:    make(chan struct{}),
+	}
+	go p.readLoop()
+	return p, nil
+}
+
+func (p *Peer) LocalAddr() *net.UDPAddr {
+	return p.conn.LocalAddr().(*net.UDPAddr)
+}
+
+func (p *Peer) SetAutoAck(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.autoAck = enabled
+}
+
+func (p *Peer) SetPlayerID(id int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.playerID = id
+}
+
+func (p *Peer) Close() {
+	close(p.done)
+	p.conn.Close()
+}
+
+func (p *Peer) readLoop() {
+	buf := make([]byte, 65536)
+	for {
+		n, addr, err := p.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		var msg prt.GameMessage
+		if err := proto.Unmarshal(buf[:n], &msg); err != nil {
+			continue
+		}
+		p.mu.Lock()
+		autoAck := p.autoAck
+		p.mu.Unlock()
+		if autoAck && needsAck(&msg) {
+			p.Ack(&msg, addr)
+		}
+		select {
+		case p.inbox <- &Received{Msg: &msg, From: addr, At: time.Now()}:
+		case <-p.done:
+			return
+		default:
+		}
+	}
+}
+
+func needsAck(msg *prt.GameMessage) bool {
+	return msg.GetAck() == nil && msg.GetAnnouncement() == nil && msg.GetDiscover() == nil
+}
+
+func (p *Peer) Send(msg *prt.GameMessage, to *net.UDPAddr) (int64, error) {
+	p.mu.Lock()
+	msg.MsgSeq = p.msgSeq
+	p.msgSeq++
+	if msg.SenderId == 0 {
+		msg.SenderId = p.playerID
+	}
+	p.mu.Unlock()
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return 0, err
+	}
+	_, err = p.conn.WriteToUDP(data, to)
+	return msg.MsgSeq, err
+}
+
+func (p *Peer) Ack(msg *prt.GameMessage, to *net.UDPAddr) {
+	p.mu.Lock()
+	playerID := p.playerID
+	p.mu.Unlock()
+	ack := &prt.GameMessage{
+		MsgSeq:     msg.GetMsgSeq(),
+		SenderId:   playerID,
+		ReceiverId: msg.GetSenderId(),
+		Type:       &prt.GameMessage_Ack{Ack: &prt.GameMessage_AckMsg{}},
+	}
+	if data, err := proto.Marshal(ack); err == nil {
+		p.conn.WriteToUDP(data, to)
+	}
+}
+
+// Inbox hands out received messages directly, for callers that handle
+// every message as it comes rather than waiting for particular ones.
+func (p *Peer) Inbox() <-chan *Received {
+	return p.inbox
+}
+
+func (p *Peer) Drain() {
+	for {
+		select {
+		case <-p.inbox:
+		default:
+			return
+		}
+	}
+}
+
+func (p *Peer) Expect(timeout time.Duration, match func(*Received) bool) (*Received, error) {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case received := <-p.inbox:
+			if match(received) {
+				return received, nil
+			}
+		case <-deadline:
+			return nil, fmt.Errorf("no matching message within %s", timeout)
+		}
+	}
+}
+
+func (p *Peer) Collect(window time.Duration, match func(*Received) bool) []*Received {
+	deadline := time.After(window)
+	collected := make([]*Received, 0)
+	for {
+		select {
+		case received := <-p.inbox:
+			if match(received) {
+				collected = append(collected, received)
+			}
+		case <-deadline:
+			return collected
+		}
+	}
+}
+
+func isAckFor(seq int64) func(*Received) bool {
+	return func(r *Received) bool {
+		return r.Msg.GetAck() != nil && r.Msg.GetMsgSeq() == seq
+	}
+}
+
+func isState(r *Received) bool {
+	return r.Msg.GetState() != nil
+}
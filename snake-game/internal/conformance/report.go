@@ -0,0 +1,61 @@
+package conformance
+
+import (
+	"fmt"
+	"io"
+)
+
+type Status int
+
+const (
+	Pass Status = iota
+	Fail
+	Skip
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "PASS"
+	case Fail:
+		return "FAIL"
+	default:
+		return "SKIP"
+	}
+}
+
+type Result struct {
+	Rule   string
+	Status Status
+	Detail string
+}
+
+type Report struct {
+	Target  string
+	Mode    string
+	Results []Result
+}
+
+func (r *Report) add(rule string, status Status, format string, args ...any) {
+	r.Results = append(r.Results, Result{Rule: rule, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if result.Status == Fail {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "=== CONFORMANCE REPORT: %s (%s) ===\n", r.Target, r.Mode)
+	counts := make(map[Status]int)
+	for _, result := range r.Results {
+		counts[result.Status]++
+		fmt.Fprintf(w, "[%s] %-28s %s\n", result.Status, result.Rule, result.Detail)
+	}
+	fmt.Fprintf(w, "--- %d passed, %d failed, %d skipped\n", counts[Pass], counts[Fail], counts[Skip])
+}
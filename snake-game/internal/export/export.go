@@ -0,0 +1,150 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"snake-game/internal/game/logic"
+	"snake-game/internal/game/palette"
+	proto "snake-game/internal/proto/gen"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultScale = 12
+	DefaultDelay = 100 * time.Millisecond
+)
+
+type Options struct {
+	Width  int32
+	Height int32
+	Scale  int
+	Delay  time.Duration
+	From   int32
+	To     int32
+}
+
+func (o Options) withDefaults() Options {
+	if o.Scale <= 1 {
+		o.Scale = DefaultScale
+	}
+	if o.Delay <= 0 {
+		o.Delay = DefaultDelay
+	}
+	return o
+}
+
+// Frames orders states by state_order, drops duplicates (a state may be
+// captured several times because of retransmissions) and keeps the range
+// [From, To]; a zero bound is open.
+func Frames(states []*proto.GameState, from, to int32) []*proto.GameState {
+	sorted := append([]*proto.GameState(nil), states...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetStateOrder() < sorted[j].GetStateOrder()
+	})
+	frames := make([]*proto.GameState, 0, len(sorted))
+	for _, state := range sorted {
+		order := state.GetStateOrder()
+		if from != 0 && order < from || to != 0 && order > to {
+			continue
+		}
+		if len(frames) > 0 && frames[len(frames)-1].GetStateOrder() == order {
+			continue
+		}
+		frames = append(frames, state)
+	}
+	return frames
+}
+
+func RenderFrame(state *proto.GameState, width, height int32, scale int) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, int(width)*scale, int(height)*scale), palette.All())
+	draw.Draw(img, img.Bounds(), image.NewUniform(palette.Background), image.Point{}, draw.Src)
+	cell := func(x, y int32, clr color.Color) {
+		if x < 0 || x >= width || y < 0 || y >= height {
+			return
+		}
+		rect := image.Rect(int(x)*scale, int(y)*scale, int(x+1)*scale-1, int(y+1)*scale-1)
+		draw.Draw(img, rect, image.NewUniform(clr), image.Point{}, draw.Src)
+	}
+	for y := int32(0); y < height; y++ {
+		for x := int32(0); x < width; x++ {
+			cell(x, y, palette.Cell)
+		}
+	}
+	for _, food := range state.GetFoods() {
+		cell(food.GetX(), food.GetY(), palette.Food)
+	}
+	for _, snake := range state.GetSnakes() {
+		for i, point := range snake.GetPoints() {
+			switch {
+			case logic.IsWallSnake(snake):
+				cell(point.GetX(), point.GetY(), palette.Wall)
+			case i == 0:
+				cell(point.GetX(), point.GetY(), palette.Head)
+			default:
+				cell(point.GetX(), point.GetY(), palette.Body)
+			}
+		}
+	}
+	return img
+}
+
+func WriteGIF(path string, states []*proto.GameState, opts Options) error {
+	opts = opts.withDefaults()
+	frames := Frames(states, opts.From, opts.To)
+	if len(frames) == 0 {
+		return errors.New("no frames in the selected range")
+	}
+	delay := int(opts.Delay / (10 * time.Millisecond))
+	anim := &gif.GIF{}
+	for _, state := range frames {
+		anim.Image = append(anim.Image, RenderFrame(state, opts.Width, opts.Height, opts.Scale))
+		anim.Delay = append(anim.Delay, delay)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating gif: %v", err)
+	}
+	defer file.Close()
+	if err := gif.EncodeAll(file, anim); err != nil {
+		return fmt.Errorf("encoding gif: %v", err)
+	}
+	return nil
+}
+
+func WritePNGs(dir string, states []*proto.GameState, opts Options) (int, error) {
+	opts = opts.withDefaults()
+	frames := Frames(states, opts.From, opts.To)
+	if len(frames) == 0 {
+		return 0, errors.New("no frames in the selected range")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("creating frame directory: %v", err)
+	}
+	for _, state := range frames {
+		path := filepath.Join(dir, fmt.Sprintf("frame-%06d.png", state.GetStateOrder()))
+		if err := writePNG(path, RenderFrame(state, opts.Width, opts.Height, opts.Scale)); err != nil {
+			return 0, err
+		}
+	}
+	return len(frames), nil
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating frame: %v", err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("encoding frame: %v", err)
+	}
+	return nil
+}
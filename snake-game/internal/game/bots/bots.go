@@ -0,0 +1,53 @@
+package bots
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"snake-game/internal/game/logic"
+	proto "snake-game/internal/proto/gen"
+	"sort"
+)
+
+// View is what a strategy sees each tick: the full state plus its own id.
+type View struct {
+	State    *proto.GameState
+	Field    *logic.Field
+	PlayerID int32
+	Rand     *rand.Rand
+}
+
+type Strategy interface {
+	Decide(v View) proto.Direction
+}
+
+type StrategyFunc func(v View) proto.Direction
+
+func (f StrategyFunc) Decide(v View) proto.Direction {
+	return f(v)
+}
+
+var strategies = map[string]Strategy{}
+
+func Register(name string, s Strategy) {
+	if _, exists := strategies[name]; exists {
+		panic(fmt.Sprintf("bot strategy %q registered twice", name))
+	}
+	strategies[name] = s
+}
+
+func Get(name string) (Strategy, error) {
+	s, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown bot strategy %q, have %v", name, Names())
+	}
+	return s, nil
+}
+
+func Names() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,133 @@
+package bots
+
+import (
+	"snake-game/internal/game/logic"
+	proto "snake-game/internal/proto/gen"
+)
+
+var directions = []proto.Direction{
+	proto.Direction_UP,
+	proto.Direction_DOWN,
+	proto.Direction_LEFT,
+	proto.Direction_RIGHT,
+}
+
+type point struct{ x, y int32 }
+
+// grid marks every cell a head could crash into next tick. Tails are left
+// free because they move away unless their snake eats.
+type grid struct {
+	field   *logic.Field
+	blocked map[point]bool
+}
+
+func newGrid(state *proto.GameState, field *logic.Field) *grid {
+	g := &grid{field: field, blocked: make(map[point]bool)}
+	for _, snake := range state.GetSnakes() {
+		points := snake.GetPoints()
+		last := len(points) - 1
+		if logic.IsWallSnake(snake) {
+			last++
+		}
+		for i := 0; i < last; i++ {
+			g.blocked[point{points[i].GetX(), points[i].GetY()}] = true
+		}
+	}
+	return g
+}
+
+func (g *grid) step(p point, dir proto.Direction) point {
+	c := &proto.GameState_Coord{X: p.x, Y: p.y}
+	switch dir {
+	case proto.Direction_UP:
+		c.Y--
+	case proto.Direction_DOWN:
+		c.Y++
+	case proto.Direction_LEFT:
+		c.X--
+	case proto.Direction_RIGHT:
+		c.X++
+	}
+	c = g.field.WrapPosition(c)
+	return point{c.X, c.Y}
+}
+
+// distance is the shortest path length on the wrapping field, ignoring
+// obstacles.
+func (g *grid) distance(a, b point) int32 {
+	dx, dy := abs(a.x-b.x), abs(a.y-b.y)
+	return min(dx, g.field.Width-dx) + min(dy, g.field.Height-dy)
+}
+
+// space counts free cells reachable from p, stopping at limit.
+func (g *grid) space(p point, limit int) int {
+	seen := map[point]bool{p: true}
+	queue := []point{p}
+	for len(queue) > 0 && len(seen) < limit {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, dir := range directions {
+			next := g.step(cur, dir)
+			if seen[next] || g.blocked[next] {
+				continue
+			}
+			seen[next] = true
+			queue = append(queue, next)
+		}
+	}
+	return len(seen)
+}
+
+func abs(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func ownSnake(v View) *proto.GameState_Snake {
+	for _, snake := range v.State.GetSnakes() {
+		if snake.GetPlayerId() == v.PlayerID {
+			return snake
+		}
+	}
+	return nil
+}
+
+func reverse(dir proto.Direction) proto.Direction {
+	switch dir {
+	case proto.Direction_UP:
+		return proto.Direction_DOWN
+	case proto.Direction_DOWN:
+		return proto.Direction_UP
+	case proto.Direction_LEFT:
+		return proto.Direction_RIGHT
+	default:
+		return proto.Direction_LEFT
+	}
+}
+
+type move struct {
+	dir  proto.Direction
+	next point
+}
+
+// safeMoves lists the moves that do not hit anything next tick.
+func safeMoves(v View, g *grid) (head point, current proto.Direction, moves []move) {
+	snake := ownSnake(v)
+	if snake == nil || len(snake.GetPoints()) == 0 {
+		return point{}, 0, nil
+	}
+	first := snake.GetPoints()[0]
+	head, current = point{first.GetX(), first.GetY()}, snake.GetHeadDirection()
+	for _, dir := range directions {
+		if dir == reverse(current) {
+			continue
+		}
+		next := g.step(head, dir)
+		if !g.blocked[next] {
+			moves = append(moves, move{dir, next})
+		}
+	}
+	return head, current, moves
+}
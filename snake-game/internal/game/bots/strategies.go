@@ -0,0 +1,74 @@
+package bots
+
+import (
+	proto "snake-game/internal/proto/gen"
+)
+
+func init() {
+	Register("straight", StrategyFunc(straight))
+	Register("random", StrategyFunc(random))
+	Register("greedy", StrategyFunc(greedy))
+	Register("cautious", StrategyFunc(cautious))
+}
+
+// straight never turns; it is the baseline every other bot should beat.
+func straight(v View) proto.Direction {
+	if snake := ownSnake(v); snake != nil {
+		return snake.GetHeadDirection()
+	}
+	return proto.Direction_UP
+}
+
+func random(v View) proto.Direction {
+	_, current, moves := safeMoves(v, newGrid(v.State, v.Field))
+	if len(moves) == 0 {
+		return current
+	}
+	return moves[v.Rand.IntN(len(moves))].dir
+}
+
+// greedy heads for the nearest food while avoiding immediate collisions.
+func greedy(v View) proto.Direction {
+	g := newGrid(v.State, v.Field)
+	_, current, moves := safeMoves(v, g)
+	if len(moves) == 0 {
+		return current
+	}
+	best, bestDist := moves[0].dir, int32(-1)
+	for _, m := range moves {
+		d := nearestFood(v, g, m.next)
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = m.dir, d
+		}
+	}
+	return best
+}
+
+// cautious prefers the move with the most reachable room and only then
+// chases food, so it rarely traps itself.
+func cautious(v View) proto.Direction {
+	g := newGrid(v.State, v.Field)
+	_, current, moves := safeMoves(v, g)
+	if len(moves) == 0 {
+		return current
+	}
+	length := len(ownSnake(v).GetPoints())
+	limit := 2*length + 8
+	best, bestSpace, bestDist := moves[0].dir, -1, int32(0)
+	for _, m := range moves {
+		space := min(g.space(m.next, limit), limit)
+		d := nearestFood(v, g, m.next)
+		if space > bestSpace || space == bestSpace && d < bestDist {
+			best, bestSpace, bestDist = m.dir, space, d
+		}
+	}
+	return best
+}
+
+func nearestFood(v View, g *grid, from point) int32 {
+	best := g.field.Width + g.field.Height
+	for _, food := range v.State.GetFoods() {
+		best = min(best, g.distance(from, point{food.GetX(), food.GetY()}))
+	}
+	return best
+}
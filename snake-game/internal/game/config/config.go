@@ -2,6 +2,8 @@ package config
 
 import (
 	"github.com/ilyakaznacheev/cleanenv"
+	"os"
+	"path/filepath"
 	proto "snake-game/internal/proto/gen"
 )
 
@@ -13,3 +15,15 @@ func LoadConfig(path string) (*proto.GameConfig, error) {
 	}
 	return &cfg, nil
 }
+
+func UserDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "snake-game")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const settingsFile = "settings.json"
+
+type Controls struct {
+	Up        []string `json:"up"`
+	Down      []string `json:"down"`
+	Left      []string `json:"left"`
+	Right     []string `json:"right"`
+	TurnLeft  []string `json:"turn_left"`
+	TurnRight []string `json:"turn_right"`
+	Relative  bool     `json:"relative"`
+	Gamepad   bool     `json:"gamepad"`
+	Deadzone  float64  `json:"deadzone"`
+}
+
+type Settings struct {
+	Controls Controls `json:"controls"`
+}
+
+func DefaultControls() Controls {
+	return Controls{
+		Up:        []string{"W", "ArrowUp"},
+		Down:      []string{"S", "ArrowDown"},
+		Left:      []string{"A", "ArrowLeft"},
+		Right:     []string{"D", "ArrowRight"},
+		TurnLeft:  []string{"A", "ArrowLeft"},
+		TurnRight: []string{"D", "ArrowRight"},
+		Gamepad:   true,
+		Deadzone:  0.3,
+	}
+}
+
+func DefaultSettings() *Settings {
+	return &Settings{Controls: DefaultControls()}
+}
+
+func settingsPath() (string, error) {
+	dir, err := UserDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %v", err)
+	}
+	return filepath.Join(dir, settingsFile), nil
+}
+
+func LoadSettings() (*Settings, error) {
+	settings := DefaultSettings()
+	path, err := settingsPath()
+	if err != nil {
+		return settings, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("reading settings: %v", err)
+	}
+	if err := json.Unmarshal(data, settings); err != nil {
+		return DefaultSettings(), fmt.Errorf("parsing settings: %v", err)
+	}
+	if settings.Controls.Deadzone <= 0 || settings.Controls.Deadzone >= 1 {
+		settings.Controls.Deadzone = DefaultControls().Deadzone
+	}
+	return settings, nil
+}
+
+func SaveSettings(settings *Settings) error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling settings: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing settings: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package controls
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"math"
+	"snake-game/internal/game/config"
+	"snake-game/internal/game/keymap"
+	proto "snake-game/internal/proto/gen"
+)
+
+// Controller reads steering from the keyboard and gamepads. Keys bound by
+// letter, digit or Space match the character typed, as in the terminal;
+// the rest, like the arrows, match the physical key.
+type Controller struct {
+	keys      *keymap.Keymap
+	chars     []rune
+	up        []ebiten.Key
+	down      []ebiten.Key
+	left      []ebiten.Key
+	right     []ebiten.Key
+	turnLeft  []ebiten.Key
+	turnRight []ebiten.Key
+	relative  bool
+	gamepad   bool
+	deadzone  float64
+	gamepads  []ebiten.GamepadID
+	stick     map[ebiten.GamepadID]proto.Direction
+}
+
+func NewController(c config.Controls) (*Controller, error) {
+	ctrl := &Controller{
+		keys:     keymap.New(c),
+		relative: c.Relative,
+		gamepad:  c.Gamepad,
+		deadzone: c.Deadzone,
+		stick:    make(map[ebiten.GamepadID]proto.Direction),
+	}
+	bindings := []struct {
+		action string
+		names  []string
+		keys   *[]ebiten.Key
+	}{
+		{"up", c.Up, &ctrl.up},
+		{"down", c.Down, &ctrl.down},
+		{"left", c.Left, &ctrl.left},
+		{"right", c.Right, &ctrl.right},
+		{"turn left", c.TurnLeft, &ctrl.turnLeft},
+		{"turn right", c.TurnRight, &ctrl.turnRight},
+	}
+	for _, binding := range bindings {
+		if _, err := ParseKeys(binding.names); err != nil {
+			return nil, fmt.Errorf("binding for %s: %v", binding.action, err)
+		}
+		physical := make([]string, 0, len(binding.names))
+		for _, name := range binding.names {
+			if !keymap.IsCharName(name) {
+				physical = append(physical, name)
+			}
+		}
+		*binding.keys, _ = ParseKeys(physical)
+	}
+	return ctrl, nil
+}
+
+func ParseKeys(names []string) ([]ebiten.Key, error) {
+	keys := make([]ebiten.Key, 0, len(names))
+	for _, name := range names {
+		var key ebiten.Key
+		if err := key.UnmarshalText([]byte(name)); err != nil {
+			return nil, fmt.Errorf("unknown key %q", name)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func anyJustPressed(keys []ebiten.Key) bool {
+	for _, key := range keys {
+		if inpututil.IsKeyJustPressed(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Poll returns the direction requested this tick. head is the current
+// direction of the player's snake and is only used for relative steering.
+func (c *Controller) Poll(head proto.Direction) (proto.Direction, bool) {
+	if dir, ok := c.pollKeyboard(head); ok {
+		return dir, true
+	}
+	if c.gamepad {
+		return c.pollGamepads()
+	}
+	return 0, false
+}
+
+func (c *Controller) pollKeyboard(head proto.Direction) (proto.Direction, bool) {
+	c.chars = ebiten.AppendInputChars(c.chars[:0])
+	for _, char := range c.chars {
+		if name, ok := keymap.CharName(char); ok {
+			if dir, ok := c.keys.Press(name, head); ok {
+				return dir, true
+			}
+		}
+	}
+	if c.relative {
+		switch {
+		case anyJustPressed(c.turnLeft):
+			return keymap.TurnLeft(head), true
+		case anyJustPressed(c.turnRight):
+			return keymap.TurnRight(head), true
+		}
+		return 0, false
+	}
+	switch {
+	case anyJustPressed(c.up):
+		return proto.Direction_UP, true
+	case anyJustPressed(c.down):
+		return proto.Direction_DOWN, true
+	case anyJustPressed(c.left):
+		return proto.Direction_LEFT, true
+	case anyJustPressed(c.right):
+		return proto.Direction_RIGHT, true
+	}
+	return 0, false
+}
+
+func (c *Controller) pollGamepads() (proto.Direction, bool) {
+	c.gamepads = ebiten.AppendGamepadIDs(c.gamepads[:0])
+	for _, id := range c.gamepads {
+		if dir, ok := c.pollDPad(id); ok {
+			return dir, true
+		}
+		if dir, ok := c.pollStick(id); ok {
+			return dir, true
+		}
+	}
+	return 0, false
+}
+
+func (c *Controller) pollDPad(id ebiten.GamepadID) (proto.Direction, bool) {
+	if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+		return 0, false
+	}
+	switch {
+	case inpututil.IsStandardGamepadButtonJustPressed(id, ebiten.StandardGamepadButtonLeftTop):
+		return proto.Direction_UP, true
+	case inpututil.IsStandardGamepadButtonJustPressed(id, ebiten.StandardGamepadButtonLeftBottom):
+		return proto.Direction_DOWN, true
+	case inpututil.IsStandardGamepadButtonJustPressed(id, ebiten.StandardGamepadButtonLeftLeft):
+		return proto.Direction_LEFT, true
+	case inpututil.IsStandardGamepadButtonJustPressed(id, ebiten.StandardGamepadButtonLeftRight):
+		return proto.Direction_RIGHT, true
+	}
+	return 0, false
+}
+
+// pollStick only reports a direction when the stick moves into a new
+// quadrant, so holding it does not resend the same steer every frame.
+func (c *Controller) pollStick(id ebiten.GamepadID) (proto.Direction, bool) {
+	var x, y float64
+	if ebiten.IsStandardGamepadLayoutAvailable(id) {
+		x = ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		y = ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	} else if ebiten.GamepadAxisCount(id) >= 2 {
+		x = ebiten.GamepadAxisValue(id, 0)
+		y = ebiten.GamepadAxisValue(id, 1)
+	}
+	dir, ok := StickDirection(x, y, c.deadzone)
+	if !ok {
+		delete(c.stick, id)
+		return 0, false
+	}
+	if last, held := c.stick[id]; held && last == dir {
+		return 0, false
+	}
+	c.stick[id] = dir
+	return dir, true
+}
+
+func StickDirection(x, y, deadzone float64) (proto.Direction, bool) {
+	if math.Hypot(x, y) < deadzone {
+		return 0, false
+	}
+	if math.Abs(x) > math.Abs(y) {
+		if x < 0 {
+			return proto.Direction_LEFT, true
+		}
+		return proto.Direction_RIGHT, true
+	}
+	if y < 0 {
+		return proto.Direction_UP, true
+	}
+	return proto.Direction_DOWN, true
+}
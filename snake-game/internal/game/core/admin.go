@@ -0,0 +1,93 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"snake-game/internal/game/match"
+	"snake-game/internal/network"
+	proto "snake-game/internal/proto/gen"
+	"strings"
+	"time"
+)
+
+func (g *Game) OnErrorReceived(message string) {
+	log.Printf("Master: %s", message)
+	s := g.session
+	if s == nil {
+		return
+	}
+	g.showNotice(s, message)
+	if message == network.GameEndedMessage || message == network.MasterLostMessage || network.IsKickMessage(message) {
+		s.End()
+	}
+}
+
+func (g *Game) showNotice(s *Session, message string) {
+	s.notice = message
+	if strings.HasPrefix(message, match.ResultsPrefix) {
+		s.results = message
+	}
+}
+
+// OnRoleChanged picks up the game loop when this node takes over as master.
+func (g *Game) OnRoleChanged(role proto.NodeRole) {
+	s := g.session
+	if s == nil {
+		return
+	}
+	switch role {
+	case proto.NodeRole_MASTER:
+		s.logic.Resume(g.networkMgr.NoWrap())
+		s.lastUpdate = time.Now()
+		s.notice = "The master left, you are hosting now"
+	case proto.NodeRole_DEPUTY:
+		s.notice = "You are the deputy"
+	}
+}
+
+// AdminTargets lists the players the master may kick.
+func (g *Game) AdminTargets(s *Session) []*proto.GamePlayer {
+	targets := make([]*proto.GamePlayer, 0)
+	for _, player := range s.logic.GetPlayers().GetPlayers() {
+		if player.GetId() != g.OwnPlayerID(s) && player.GetRole() != proto.NodeRole_VIEWER {
+			targets = append(targets, player)
+		}
+	}
+	return targets
+}
+
+func (g *Game) Kick(s *Session, playerID int32) {
+	if err := g.networkMgr.KickPlayer(playerID, ""); err != nil {
+		s.notice = fmt.Sprintf("Kick failed: %v", err)
+	}
+}
+
+func (g *Game) TogglePause(s *Session) {
+	s.paused = !s.paused
+}
+
+func (g *Game) CanJoin() bool {
+	return g.networkMgr.CanJoin()
+}
+
+func (g *Game) ToggleJoining() {
+	g.networkMgr.SetCanJoin(!g.networkMgr.CanJoin())
+}
+
+func (g *Game) Save(s *Session) {
+	if _, err := g.saveGame(s); err != nil {
+		s.notice = fmt.Sprintf("Save failed: %v", err)
+	} else {
+		s.notice = fmt.Sprintf("Saved '%s'", s.name)
+	}
+}
+
+// EndGame ends the game for everyone. It is over, so it is not saved when
+// the session ends and any earlier save is deleted.
+func (g *Game) EndGame(s *Session) {
+	g.networkMgr.EndGame()
+	s.over = true
+	g.deleteSave(s)
+	s.notice = network.GameEndedMessage
+	s.End()
+}
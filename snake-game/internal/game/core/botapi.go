@@ -0,0 +1,36 @@
+package core
+
+import (
+	"fmt"
+	proto "snake-game/internal/proto/gen"
+)
+
+// botPlayer lets the bot API play as this node, steering through the same
+// path as the keyboard.
+type botPlayer struct {
+	g *Game
+}
+
+func (p botPlayer) PlayerID() (int32, bool) {
+	p.g.Lock()
+	defer p.g.Unlock()
+	s := p.g.session
+	if s == nil {
+		return 0, false
+	}
+	return p.g.OwnPlayerID(s), true
+}
+
+func (p botPlayer) Role() proto.NodeRole {
+	return p.g.networkMgr.GetRole()
+}
+
+func (p botPlayer) Steer(direction proto.Direction) error {
+	p.g.Lock()
+	defer p.g.Unlock()
+	s := p.g.session
+	if s == nil || s.Ended() {
+		return fmt.Errorf("no game running")
+	}
+	return p.g.Steer(s, direction)
+}
@@ -0,0 +1,18 @@
+package core
+
+import "snake-game/internal/game/config"
+
+// Frontend shows the games the lobby starts. The window and the terminal
+// each live in a package of their own, so a binary only links the one it
+// plays in.
+type Frontend interface {
+	// Run runs lobby, which returns once the player quits. A front-end that
+	// must own the main thread runs lobby in another goroutine.
+	Run(lobby func())
+	// Play shows s and returns once it ends or the player leaves it.
+	Play(g *Game, s *Session)
+	// SetControls applies the key bindings from the settings.
+	SetControls(c config.Controls) error
+	// CheckKeys refuses key names the front-end cannot bind.
+	CheckKeys(names []string) error
+}
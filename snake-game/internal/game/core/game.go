@@ -2,189 +2,312 @@ package core
 
 import (
 	"fmt"
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	gproto "google.golang.org/protobuf/proto"
 	"log"
+	"net"
 	"os"
+	"snake-game/internal/botapi"
 	"snake-game/internal/game/config"
-	"snake-game/internal/game/graphics"
 	"snake-game/internal/game/logic"
+	"snake-game/internal/game/maps"
+	"snake-game/internal/game/match"
+	"snake-game/internal/game/saves"
+	"snake-game/internal/game/stats"
 	"snake-game/internal/game/ui"
 	"snake-game/internal/network"
 	proto "snake-game/internal/proto/gen"
+	"snake-game/internal/spectator"
+	"sync"
 	"time"
 )
 
+const directTimeout = 3 * time.Second
+
+// Game is the lobby and whichever game it plays. mu guards the session:
+// the network manager takes it through Lock before calling back, and the
+// front-ends hold it while they step, steer or draw.
 type Game struct {
-	logic           *logic.GameLogic
-	renderer        *graphics.Renderer
-	lastUpdate      time.Time
-	lastScoreUpdate time.Time
-	ui              *ui.ConsoleUI
-	games           []*proto.GameAnnouncement
-	networkMgr      *network.Manager
-	cleanupDone     bool
+	mu          sync.Mutex
+	ui          *ui.ConsoleUI
+	browser     *ui.GameBrowser
+	networkMgr  *network.Manager
+	matchStore  *stats.Store
+	saves       *saves.Store
+	settings    *config.Settings
+	spectator   *spectator.Server
+	botAPI      *botapi.Server
+	frontend    Frontend
+	connectAddr string
+	session     *Session
 }
 
-func NewGame() *Game {
+// NewGame sets up the lobby; the games it starts are shown on frontend.
+func NewGame(frontend Frontend) *Game {
 	game := &Game{
-		lastUpdate: time.Now(),
-		ui:         ui.NewConsoleUI(),
+		ui:       ui.NewConsoleUI(),
+		browser:  ui.NewGameBrowser(),
+		frontend: frontend,
+	}
+	store, err := stats.DefaultStore()
+	if err != nil {
+		log.Printf("Match history disabled: %v", err)
+	}
+	game.matchStore = store
+	if saveStore, err := saves.DefaultStore(); err == nil {
+		game.saves = saveStore
+	} else {
+		log.Printf("Saving games disabled: %v", err)
 	}
+	settings, err := config.LoadSettings()
+	if err != nil {
+		log.Printf("Using default settings: %v", err)
+	}
+	game.applySettings(settings)
 	game.networkMgr = network.NewNetworkManager(proto.NodeRole_NORMAL, nil)
-	game.networkMgr.SetGameAnnouncementListener(game)
+	game.networkMgr.SetGameBrowserListener(game)
 	game.networkMgr.SetGameStateListener(game)
 	game.networkMgr.SetGameJoinListener(game)
 	game.networkMgr.SetSteerListener(game)
-	if err := game.networkMgr.Start(); err != nil {
-		log.Printf("Failed to start network manager: %v", err)
+	game.networkMgr.SetErrorListener(game)
+	game.networkMgr.SetRoleListener(game)
+	if sessions, err := network.DefaultSessionStore(); err == nil {
+		game.networkMgr.SetSessionStore(sessions)
+	} else {
+		log.Printf("Rejoin sessions disabled: %v", err)
+	}
+	if addr := os.Getenv("SPECTATE_ADDR"); addr != "" {
+		game.spectator = spectator.NewServer()
+		if err := game.spectator.Start(addr); err != nil {
+			log.Printf("Spectator mode disabled: %v", err)
+			game.spectator = nil
+		}
+	}
+	// The bot API is off unless asked for: BOT_API=1 serves it on loopback,
+	// BOT_API_ADDR on another address, and BOT_API_PUBLIC=1 lets that
+	// address be one other machines reach.
+	botAddr := os.Getenv("BOT_API_ADDR")
+	if botAddr == "" && os.Getenv("BOT_API") == "1" {
+		botAddr = botapi.DefaultAddr
+	}
+	if botAddr != "" {
+		game.botAPI = botapi.NewServer(botPlayer{game})
+		if err := game.botAPI.Start(botAddr, os.Getenv("BOT_API_PUBLIC") == "1"); err != nil {
+			log.Printf("Bot API disabled: %v", err)
+			game.botAPI = nil
+		}
 	}
 	return game
 }
 
-func (g *Game) OnGameAnnouncement(games []*proto.GameAnnouncement) {
-	g.games = games
+func (g *Game) Lock() {
+	g.mu.Lock()
+}
+
+func (g *Game) Unlock() {
+	g.mu.Unlock()
+}
+
+func (g *Game) OnGameAdded(game *proto.GameAnnouncement, master *net.UDPAddr, latency time.Duration) {
+	g.browser.Upsert(game, master, latency)
+}
+
+func (g *Game) OnGameUpdated(game *proto.GameAnnouncement, master *net.UDPAddr, latency time.Duration) {
+	g.browser.Upsert(game, master, latency)
+}
+
+func (g *Game) OnGameRemoved(gameName string, master *net.UDPAddr) {
+	g.browser.Remove(gameName, master)
 }
 
 func (g *Game) OnGameStateReceived(state *proto.GameState) {
-	g.logic.SetState(state)
+	s := g.session
+	if s == nil {
+		return
+	}
+	s.logic.SetState(state)
+	s.tracker.Observe(state)
+	if g.spectator != nil {
+		g.spectator.OnGameStateReceived(state)
+	}
+	if g.botAPI != nil {
+		g.botAPI.OnGameStateReceived(state)
+	}
 }
 
 func (g *Game) OnGameAddPlayer(player *proto.GamePlayer) {
-	g.logic.AddPlayer(player)
+	if s := g.session; s != nil {
+		s.logic.AddPlayer(player)
+	}
 }
 
 func (g *Game) OnSteerReceived(playerID int32, direction proto.Direction) error {
-	return g.logic.SteerSnake(playerID, direction)
+	s := g.session
+	if s == nil {
+		return fmt.Errorf("no game running")
+	}
+	return s.logic.SteerSnake(playerID, direction)
 }
 
 func (g *Game) GetLogic() *logic.GameLogic {
-	return g.logic
+	if s := g.session; s != nil {
+		return s.logic
+	}
+	return nil
 }
 
-func (g *Game) Update() error {
-	if ebiten.IsWindowBeingClosed() {
-		return g.initiateShutdown()
+// Step advances the game by one tick once the state delay has passed, if
+// this node is the master. Front-ends call it every frame.
+func (g *Game) Step(s *Session) error {
+	if g.networkMgr.GetRole() != proto.NodeRole_MASTER {
+		return nil
 	}
-	g.handleInput()
-	if g.networkMgr.GetRole() == proto.NodeRole_MASTER {
-		now := time.Now()
-		interval := time.Duration(g.logic.Config.StateDelayMs) * time.Millisecond
-		if now.Sub(g.lastUpdate) >= interval {
-			if err := g.logic.Update(); err != nil {
-				return fmt.Errorf("error updating game: %v", err)
-			}
-			g.lastUpdate = now
-			err := g.networkMgr.SendState(g.logic.GetState())
-			if err != nil {
-				return fmt.Errorf("error updating game: %v", err)
-			}
+	now := time.Now()
+	interval := time.Duration(s.logic.Config.StateDelayMs) * time.Millisecond
+	if now.Sub(s.lastUpdate) < interval {
+		return nil
+	}
+	s.lastUpdate = now
+	if !s.paused {
+		if err := g.advance(s); err != nil {
+			return fmt.Errorf("error updating game: %v", err)
+		}
+		s.tracker.Observe(s.logic.GetState())
+		if g.spectator != nil {
+			g.spectator.OnGameStateReceived(s.logic.GetState())
+		}
+		if g.botAPI != nil {
+			g.botAPI.OnGameStateReceived(s.logic.GetState())
 		}
 	}
-	now := time.Now()
-	if now.Sub(g.lastScoreUpdate) >= 250*time.Millisecond {
-		ui.ShowScores(g.logic)
-		g.lastScoreUpdate = now
+	if err := g.networkMgr.SendState(s.logic.GetState()); err != nil {
+		return fmt.Errorf("error updating game: %v", err)
 	}
 	return nil
 }
 
-func (g *Game) initiateShutdown() error {
-	if g.cleanupDone {
-		return nil
+func (g *Game) advance(s *Session) error {
+	if s.match == nil {
+		return s.logic.Update()
 	}
-	g.cleanupDone = true
-	g.cleanup()
-	return ebiten.Termination
-}
-
-func (g *Game) cleanup() {
-	if g.networkMgr != nil {
-		g.networkMgr.Close()
-	}
-}
-
-func (g *Game) handleInput() {
-	var newDirection proto.Direction
-	keyPressed := false
-	if inpututil.IsKeyJustPressed(ebiten.KeyW) || inpututil.IsKeyJustPressed(ebiten.KeyUp) {
-		newDirection = proto.Direction_UP
-		keyPressed = true
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyS) || inpututil.IsKeyJustPressed(ebiten.KeyDown) {
-		newDirection = proto.Direction_DOWN
-		keyPressed = true
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyA) || inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
-		newDirection = proto.Direction_LEFT
-		keyPressed = true
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyD) || inpututil.IsKeyJustPressed(ebiten.KeyRight) {
-		newDirection = proto.Direction_RIGHT
-		keyPressed = true
-	}
-	if keyPressed {
-		if g.networkMgr.GetRole() == proto.NodeRole_MASTER {
-			var masterPlayerID int32
-			for _, val := range g.logic.GetPlayers().GetPlayers() {
-				if val.GetRole() == proto.NodeRole_MASTER {
-					masterPlayerID = val.GetId()
-					break
-				}
-			}
-			err := g.OnSteerReceived(masterPlayerID, newDirection)
-			if err != nil {
-				log.Printf("Error steering master snake: %v", err)
+	notices, err := s.match.Step()
+	for _, notice := range notices {
+		g.showNotice(s, notice)
+		g.networkMgr.BroadcastNotice(notice)
+	}
+	return err
+}
+
+func (g *Game) applySettings(settings *config.Settings) {
+	if err := g.frontend.SetControls(settings.Controls); err != nil {
+		log.Printf("Invalid controls, using defaults: %v", err)
+		settings = config.DefaultSettings()
+		g.frontend.SetControls(settings.Controls)
+	}
+	g.settings = settings
+}
+
+func (g *Game) Role() proto.NodeRole {
+	return g.networkMgr.GetRole()
+}
+
+func (g *Game) OwnPlayerID(s *Session) int32 {
+	if g.networkMgr.GetRole() == proto.NodeRole_MASTER {
+		for _, val := range s.logic.GetPlayers().GetPlayers() {
+			if val.GetRole() == proto.NodeRole_MASTER {
+				return val.GetId()
 			}
-		} else if g.networkMgr.GetRole() == proto.NodeRole_NORMAL {
-			g.networkMgr.SendSteer(newDirection)
-		} else if g.networkMgr.GetRole() == proto.NodeRole_DEPUTY {
-			g.networkMgr.SendSteer(newDirection)
 		}
 	}
+	return g.networkMgr.GetID()
 }
 
-func (g *Game) Draw(screen *ebiten.Image) {
-	g.updateCellSize()
-	g.renderer.Draw(screen)
+// HeadDirection is where the player's snake is heading, for relative
+// steering.
+func (g *Game) HeadDirection(s *Session) proto.Direction {
+	if snake := s.logic.GetSnakeByPlayerID(g.OwnPlayerID(s)); snake != nil {
+		return snake.GetHeadDirection()
+	}
+	return proto.Direction_UP
 }
 
-func (g *Game) updateCellSize() {
-	if g.renderer != nil && g.logic != nil {
-		field := g.logic.GetField()
-		if field != nil {
-			width, height := ebiten.WindowSize()
-			cellWidth := width / int(field.Width)
-			cellHeight := height / int(field.Height)
-			cellSize := min(cellWidth, cellHeight)
-			g.renderer.SetCellSize(cellSize)
+// Steer turns the player's snake, directly on the master and with a
+// SteerMsg anywhere else.
+func (g *Game) Steer(s *Session, newDirection proto.Direction) error {
+	switch g.networkMgr.GetRole() {
+	case proto.NodeRole_MASTER:
+		if err := s.logic.SteerSnake(g.OwnPlayerID(s), newDirection); err != nil {
+			return fmt.Errorf("steering master snake: %v", err)
 		}
+		return nil
+	case proto.NodeRole_NORMAL, proto.NodeRole_DEPUTY:
+		return g.networkMgr.SendSteer(newDirection)
 	}
+	return fmt.Errorf("viewers have no snake to steer")
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// RequestPlay asks the master for a snake while watching.
+func (g *Game) RequestPlay(s *Session) {
+	if err := g.networkMgr.RequestPlay(); err != nil {
+		s.notice = err.Error()
+		return
 	}
-	return b
+	s.notice = "Asked the master for a snake"
+}
+
+// SetMulticastGroup, SetMulticastInterface and SetListenAddr configure the
+// sockets, which open when Start is called.
+func (g *Game) SetMulticastGroup(group string) {
+	g.networkMgr.SetMulticastGroup(group)
+}
+
+func (g *Game) SetMulticastInterface(name string) {
+	g.networkMgr.SetMulticastInterface(name)
 }
 
-func (g *Game) Layout(width, height int) (int, int) {
-	g.updateCellSize()
-	return width, height
+func (g *Game) SetListenAddr(addr string) {
+	g.networkMgr.SetListenAddr(addr)
 }
 
+// SetConnectAddr makes the lobby open by asking the master at hostport for
+// its games, then offering to join one of them.
+func (g *Game) SetConnectAddr(hostport string) {
+	g.connectAddr = hostport
+}
+
+// Start runs the console lobby on the front-end and exits the process once
+// the player quits.
 func (g *Game) Start() {
-	ebiten.SetWindowSize(600, 600)
-	ebiten.SetWindowTitle("Snake Game")
+	if err := g.networkMgr.Start(); err != nil {
+		log.Printf("Failed to start network manager: %v", err)
+	}
+	g.frontend.Run(g.lobby)
+	g.shutdown()
+	os.Exit(0)
+}
 
+func (g *Game) lobby() {
+	if g.connectAddr != "" {
+		g.connect(g.connectAddr)
+	}
 	for {
 		mo := g.ui.ShowMainMenu()
 		switch mo {
 		case ui.StartNewGame:
 			g.startNewGame()
+		case ui.ResumeGame:
+			g.resumeGame()
 		case ui.JoinGame:
 			g.joinGame()
+		case ui.ConnectAddress:
+			g.connect(g.ui.ReadAddress())
 		case ui.ShowGames:
 			g.showGames()
+		case ui.Leaderboard:
+			g.showLeaderboard()
+		case ui.Settings:
+			g.showSettings()
 		case ui.Exit:
+			g.shutdown()
 			fmt.Println("Goodbye!")
 			os.Exit(0)
 		default:
@@ -202,66 +325,237 @@ func (g *Game) startNewGame() {
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
-	g.logic = logic.NewGameLogic(cfg)
-	g.renderer = graphics.NewRenderer(g.logic)
+	gl := logic.NewGameLogic(cfg)
+	if mapName := os.Getenv("MAP"); mapName != "" {
+		gameMap, err := maps.Load(mapName)
+		if err != nil {
+			log.Fatalf("Failed to load map: %v", err)
+		}
+		gl.SetMap(gameMap)
+		fmt.Printf("Loaded map '%s' (%dx%d)\n", gameMap.Name, gameMap.Width, gameMap.Height)
+	}
 	gameName := g.ui.ReadGameName()
 	playerName := g.ui.ReadPlayerName()
+	matchOpts := g.ui.ReadMatchOptions()
 	fmt.Printf("Creating game '%s' for player '%s'\n", gameName, playerName)
-	g.logic.AddPlayer(g.logic.NewPlayer(playerName, proto.PlayerType_HUMAN, proto.NodeRole_MASTER, logic.GeneratePlayerID()))
+	master := gl.NewPlayer(playerName, proto.PlayerType_HUMAN, proto.NodeRole_MASTER, logic.GeneratePlayerID())
+	gl.AddPlayer(master)
+	gl.Init()
+	s := newSession(gameName, gl)
+	if matchOpts.Enabled() {
+		s.match = match.New(matchOpts, gl)
+	}
+	g.host(s, master, true)
+}
+
+func (g *Game) resumeGame() {
+	if g.saves == nil {
+		fmt.Println("Saved games are not available")
+		return
+	}
+	games, err := g.saves.List()
+	if err != nil {
+		fmt.Printf("Failed to list saved games: %v\n", err)
+		return
+	}
+	if len(games) == 0 {
+		fmt.Println("No saved games")
+		return
+	}
+	labels := make([]string, len(games))
+	for i, saved := range games {
+		labels[i] = fmt.Sprintf("%s (saved %s)", saved.Game, saved.Saved.Format("2006-01-02 15:04"))
+	}
+	choice := g.ui.ReadSavedGame(labels)
+	if choice < 0 {
+		return
+	}
+	saved := games[choice]
+	gl, master, err := saved.Resume()
+	if err != nil {
+		fmt.Printf("Failed to resume '%s': %v\n", saved.Game, err)
+		return
+	}
+	g.networkMgr.SetNextSeq(saved.NextSeq)
+	g.networkMgr.RestoreSessions(saved.Sessions)
+	fmt.Printf("Resuming game '%s' as '%s'\n", saved.Game, master.GetName())
+	g.host(newSession(saved.Game, gl), master, saved.CanJoin)
+}
+
+func (g *Game) host(s *Session, master *proto.GamePlayer, canJoin bool) {
+	g.Lock()
 	gameAnnounce := &proto.GameAnnouncement{
-		Config:   g.logic.Config,
-		Players:  g.logic.GetPlayers(),
-		GameName: gameName,
-		CanJoin:  true,
+		Config:   s.logic.Config,
+		Players:  s.logic.GetPlayers(),
+		GameName: s.name,
+		CanJoin:  canJoin,
 	}
-	g.logic.Init()
-	g.networkMgr.ChangeRole(g.logic.GetPlayers().GetPlayers()[0], proto.NodeRole_MASTER)
 	g.networkMgr.SetGameAnnouncement(gameAnnounce)
 	g.networkMgr.SetActivityManager(gameAnnounce.Config.GetStateDelayMs())
-	if err := ebiten.RunGame(g); err != nil {
-		log.Fatal(err)
-	}
+	g.networkMgr.ChangeRole(master, proto.NodeRole_MASTER)
+	g.Unlock()
+	g.play(s)
 }
 
 func (g *Game) joinGame() {
 	gameName, playerRole := g.ui.ReadJoinInfo()
-	var targetGame *proto.GameAnnouncement
-	for name, gameInfo := range g.networkMgr.AvailableGames {
-		if gameName == name && gameInfo.Announcement.CanJoin {
-			targetGame = gameInfo.Announcement
-			break
-		}
-	}
-	if targetGame == nil {
+	gameInfo := g.pickGame(gameName)
+	if gameInfo == nil {
 		fmt.Println("Game not found")
 		return
 	}
+	targetGame := gproto.Clone(gameInfo.Announcement).(*proto.GameAnnouncement)
 	cfg := targetGame.Config
-	g.logic = logic.NewGameLogic(cfg)
 	playerName := g.ui.ReadPlayerName()
-	g.networkMgr.JoinNotify = make(chan int32, 1)
-	err := g.networkMgr.SendJoinRequest(proto.PlayerType_HUMAN, playerName, gameName, playerRole)
+	joined, err := g.networkMgr.SendJoinRequest(proto.PlayerType_HUMAN, playerName, gameInfo, playerRole)
 	if err != nil {
 		fmt.Printf("Failed to send join request: %v\n", err)
 		return
 	}
 	fmt.Printf("Join request sent for game '%s'. Waiting for response...\n", gameName)
 	select {
-	case playerID := <-g.networkMgr.JoinNotify:
-		g.renderer = graphics.NewRenderer(g.logic)
+	case playerID := <-joined:
+		s := newSession(gameName, logic.NewGameLogic(cfg))
+		g.Lock()
 		g.networkMgr.SetGameAnnouncement(targetGame)
-		fmt.Printf("Successfully joined as %s! Player ID: %d\n", playerRole, playerID)
 		g.networkMgr.SetActivityManager(targetGame.Config.GetStateDelayMs())
-		if err := ebiten.RunGame(g); err != nil {
-			log.Fatal(err)
-		}
+		g.Unlock()
+		fmt.Printf("Successfully joined as %s! Player ID: %d\n", playerRole, playerID)
+		g.play(s)
 	case <-time.After(5 * time.Second):
 		fmt.Println("Join timeout: no response from game master")
+		g.networkMgr.LeaveGame()
+	}
+}
+
+// pickGame finds a joinable game called gameName, asking which master to
+// join when several announce that name.
+func (g *Game) pickGame(gameName string) *network.GameInfo {
+	games := make([]*network.GameInfo, 0)
+	for _, info := range g.networkMgr.FindGames(gameName) {
+		if info.Announcement.GetCanJoin() {
+			games = append(games, info)
+		}
+	}
+	switch len(games) {
+	case 0:
+		return nil
+	case 1:
+		return games[0]
+	}
+	labels := make([]string, len(games))
+	for i, info := range games {
+		labels[i] = fmt.Sprintf("%s at %s (%d players)", gameName, info.MasterAddr, len(info.Announcement.GetPlayers().GetPlayers()))
+	}
+	choice := g.ui.ReadMaster(labels)
+	if choice < 0 {
+		return nil
 	}
+	return games[choice]
+}
 
-	g.networkMgr.JoinNotify = nil
+func (g *Game) connect(hostport string) {
+	fmt.Printf("Asking %s for games...\n", hostport)
+	games, err := g.networkMgr.ConnectTo(hostport, directTimeout)
+	if err != nil {
+		fmt.Printf("Failed to connect: %v\n", err)
+		return
+	}
+	for _, info := range games {
+		fmt.Printf("Found game '%s' with %d players\n", info.Announcement.GetGameName(), len(info.Announcement.GetPlayers().GetPlayers()))
+	}
+	g.joinGame()
 }
 
 func (g *Game) showGames() {
-	g.ui.ShowGameList(g.games)
+	filter, sortKey := g.ui.ReadBrowseOptions()
+	g.browser.StartLive(filter, sortKey)
+	g.ui.WaitForEnter()
+	g.browser.StopLive()
+}
+
+func (g *Game) saveMatch(s *Session) {
+	if g.matchStore == nil {
+		return
+	}
+	match := s.tracker.Finish()
+	if len(match.Players) == 0 {
+		return
+	}
+	if err := g.matchStore.Append(match); err != nil {
+		log.Printf("Failed to save match summary: %v", err)
+	}
+}
+
+func (g *Game) showLeaderboard() {
+	if g.matchStore == nil {
+		fmt.Println("Match history is not available")
+		return
+	}
+	matches, err := g.matchStore.Load()
+	if err != nil {
+		fmt.Printf("Failed to load match history: %v\n", err)
+		return
+	}
+	for {
+		switch g.ui.ReadLeaderboardView() {
+		case ui.AllTimeView:
+			g.ui.ShowLeaderboard("ALL-TIME", stats.AllTime(matches))
+		case ui.PlayerView:
+			name := g.ui.ReadLeaderboardPlayer()
+			g.ui.ShowPlayerHistory(name, stats.ForPlayer(matches, name))
+		case ui.ConfigView:
+			g.ui.ShowConfigLeaderboards(stats.ByConfig(matches))
+		case ui.BackView:
+			return
+		}
+	}
+}
+
+func (g *Game) showSettings() {
+	c := g.settings.Controls
+	rebind := func(action string, target *[]string) {
+		names := g.ui.ReadKeyNames(action)
+		if len(names) == 0 {
+			fmt.Println("Binding unchanged")
+			return
+		}
+		if err := g.frontend.CheckKeys(names); err != nil {
+			fmt.Printf("Binding unchanged: %v\n", err)
+			return
+		}
+		*target = names
+	}
+	for {
+		switch g.ui.ReadSettingsOption(c) {
+		case ui.RebindUp:
+			rebind("up", &c.Up)
+		case ui.RebindDown:
+			rebind("down", &c.Down)
+		case ui.RebindLeft:
+			rebind("left", &c.Left)
+		case ui.RebindRight:
+			rebind("right", &c.Right)
+		case ui.RebindTurnLeft:
+			rebind("turn left", &c.TurnLeft)
+		case ui.RebindTurnRight:
+			rebind("turn right", &c.TurnRight)
+		case ui.ToggleRelative:
+			c.Relative = !c.Relative
+		case ui.ToggleGamepad:
+			c.Gamepad = !c.Gamepad
+		case ui.SetDeadzone:
+			c.Deadzone = g.ui.ReadDeadzone()
+		case ui.ResetControls:
+			c = config.DefaultControls()
+		case ui.SaveAndBack:
+			settings := *g.settings
+			settings.Controls = c
+			g.applySettings(&settings)
+			if err := config.SaveSettings(g.settings); err != nil {
+				fmt.Printf("Failed to save settings: %v\n", err)
+			}
+			return
+		}
+	}
 }
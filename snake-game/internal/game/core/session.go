@@ -0,0 +1,160 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"snake-game/internal/game/logic"
+	"snake-game/internal/game/match"
+	"snake-game/internal/game/saves"
+	"snake-game/internal/game/stats"
+	proto "snake-game/internal/proto/gen"
+	"sync"
+	"time"
+)
+
+// Session is one hosted or joined game. Everything that must not carry over
+// into the next game lives here, and the lobby drops it once done is closed.
+type Session struct {
+	name         string
+	logic        *logic.GameLogic
+	tracker      *stats.Tracker
+	lastUpdate   time.Time
+	paused       bool
+	notice       string
+	match        *match.Match
+	results      string
+	over         bool
+	done         chan struct{}
+	endOnce      sync.Once
+	teardownOnce sync.Once
+}
+
+func newSession(name string, gl *logic.GameLogic) *Session {
+	return &Session{
+		name:       name,
+		logic:      gl,
+		tracker:    stats.NewTracker(name, gl.Config),
+		lastUpdate: time.Now(),
+		done:       make(chan struct{}),
+	}
+}
+
+// End leaves the game; the lobby takes over once the front-end returns.
+func (s *Session) End() {
+	s.endOnce.Do(func() { close(s.done) })
+}
+
+func (s *Session) Ended() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Session) Name() string {
+	return s.name
+}
+
+func (s *Session) Logic() *logic.GameLogic {
+	return s.logic
+}
+
+// Notice is the latest message for the player, from the master or about
+// something they did.
+func (s *Session) Notice() string {
+	return s.notice
+}
+
+func (s *Session) SetNotice(notice string) {
+	s.notice = notice
+}
+
+// Results holds the final standings once a match is over.
+func (s *Session) Results() string {
+	return s.results
+}
+
+func (s *Session) Paused() bool {
+	return s.paused
+}
+
+// play shows the session on the front-end and blocks until it ends.
+func (g *Game) play(s *Session) {
+	g.Lock()
+	g.session = s
+	g.Unlock()
+	if g.spectator != nil {
+		g.spectator.SetGame(s.name, s.logic.Config)
+	}
+	if g.botAPI != nil {
+		g.botAPI.SetGame(s.name)
+	}
+	g.frontend.Play(g, s)
+	g.endSession(s)
+}
+
+func (g *Game) endSession(s *Session) {
+	s.teardownOnce.Do(func() {
+		g.Lock()
+		defer g.Unlock()
+		s.End()
+		if g.networkMgr.GetRole() == proto.NodeRole_MASTER && !s.over {
+			if path, err := g.saveGame(s); err != nil {
+				fmt.Printf("Failed to save '%s': %v\n", s.name, err)
+			} else {
+				fmt.Printf("Saved '%s' to %s\n", s.name, path)
+			}
+		}
+		g.networkMgr.LeaveGame()
+		g.saveMatch(s)
+		if g.session == s {
+			g.session = nil
+		}
+		if s.notice != "" {
+			fmt.Println(s.notice)
+		}
+		fmt.Printf("Left '%s', back in the lobby\n", s.name)
+	})
+}
+
+// saveGame snapshots the hosted game so it can be resumed from the menu.
+func (g *Game) saveGame(s *Session) (string, error) {
+	if g.saves == nil {
+		return "", fmt.Errorf("saving is not available")
+	}
+	saved, err := saves.Capture(s.name, g.OwnPlayerID(s), s.logic, g.networkMgr)
+	if err != nil {
+		return "", err
+	}
+	return g.saves.Save(saved, "")
+}
+
+// deleteSave drops the save of a game that is over, so it is not offered
+// for resuming.
+func (g *Game) deleteSave(s *Session) {
+	if g.saves == nil {
+		return
+	}
+	if err := g.saves.Delete(s.name); err != nil {
+		log.Printf("Failed to delete the save of '%s': %v", s.name, err)
+	}
+}
+
+func (g *Game) shutdown() {
+	if s := g.session; s != nil {
+		g.endSession(s)
+	}
+	g.networkMgr.Close()
+	if g.spectator != nil {
+		g.spectator.Close()
+	}
+	if g.botAPI != nil {
+		g.botAPI.Close()
+	}
+}
@@ -2,9 +2,9 @@ package graphics
 
 import (
 	"github.com/hajimehoshi/ebiten/v2"
-	"golang.org/x/image/colornames"
 	"image/color"
 	"snake-game/internal/game/logic"
+	"snake-game/internal/game/palette"
 )
 
 type Renderer struct {
@@ -19,7 +19,7 @@ func NewRenderer(logic *logic.GameLogic) *Renderer {
 }
 
 func (r *Renderer) Draw(screen *ebiten.Image) {
-	screen.Fill(color.RGBA{R: 0x1a, G: 0x1f, B: 0x2d, A: 0xff})
+	screen.Fill(palette.Background)
 	r.drawGrid(screen)
 	r.drawFood(screen)
 	r.drawSnakes(screen)
@@ -29,7 +29,7 @@ func (r *Renderer) drawGrid(screen *ebiten.Image) {
 	field := r.logic.GetField()
 	for y := 0; int32(y) < field.Height; y++ {
 		for x := 0; int32(x) < field.Width; x++ {
-			r.drawCell(screen, x, y, color.RGBA{R: 0x2d, G: 0x32, B: 0x45, A: 0xff})
+			r.drawCell(screen, x, y, palette.Cell)
 		}
 	}
 }
@@ -38,7 +38,7 @@ func (r *Renderer) drawFood(screen *ebiten.Image) {
 	foods := r.logic.GetFoods()
 	for _, food := range foods {
 		if food != nil {
-			r.drawCell(screen, int(food.X), int(food.Y), colornames.Red)
+			r.drawCell(screen, int(food.X), int(food.Y), palette.Food)
 		}
 	}
 }
@@ -47,11 +47,17 @@ func (r *Renderer) drawSnakes(screen *ebiten.Image) {
 	snakes := r.logic.GetSnakes()
 	for _, snake := range snakes {
 		points := snake.GetPoints()
+		if logic.IsWallSnake(snake) {
+			for _, point := range points {
+				r.drawCell(screen, int(point.X), int(point.Y), palette.Wall)
+			}
+			continue
+		}
 		for i, point := range points {
 			if i == 0 {
-				r.drawCell(screen, int(point.X), int(point.Y), colornames.Green)
+				r.drawCell(screen, int(point.X), int(point.Y), palette.Head)
 			} else {
-				r.drawCell(screen, int(point.X), int(point.Y), colornames.Hotpink)
+				r.drawCell(screen, int(point.X), int(point.Y), palette.Body)
 			}
 		}
 	}
@@ -0,0 +1,178 @@
+package headless
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"snake-game/internal/game/saves"
+	"snake-game/internal/network"
+	proto "snake-game/internal/proto/gen"
+	"strconv"
+	"strings"
+)
+
+func (h *Host) SetPaused(paused bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.paused = paused
+}
+
+func (h *Host) SetCanJoin(canJoin bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.networkMgr.SetCanJoin(canJoin)
+}
+
+func (h *Host) Kick(nameOrID string, reason string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	player := h.findPlayer(nameOrID)
+	if player == nil {
+		return fmt.Errorf("no player %q", nameOrID)
+	}
+	return h.networkMgr.KickPlayer(player.GetId(), reason)
+}
+
+func (h *Host) findPlayer(nameOrID string) *proto.GamePlayer {
+	id, err := strconv.ParseInt(nameOrID, 10, 32)
+	for _, player := range h.logic.GetPlayers().GetPlayers() {
+		if player.GetName() == nameOrID || err == nil && player.GetId() == int32(id) {
+			return player
+		}
+	}
+	return nil
+}
+
+// Save writes the running game to path, or to the default store when path
+// is empty, and returns where it went.
+func (h *Host) Save(path string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.saves == nil {
+		return "", fmt.Errorf("saving is not enabled")
+	}
+	saved, err := saves.Capture(h.gameName, h.master.GetId(), h.logic, h.networkMgr)
+	if err != nil {
+		return "", err
+	}
+	return h.saves.Save(saved, path)
+}
+
+// End ends the game for everyone. It is over, so its save is deleted.
+func (h *Host) End() {
+	h.endOnce.Do(func() {
+		h.Stop()
+		h.mu.Lock()
+		if h.saves != nil {
+			if err := h.saves.Delete(h.gameName); err != nil {
+				log.Printf("Failed to delete the saved game: %v", err)
+			}
+		}
+		h.networkMgr.EndGame()
+		h.mu.Unlock()
+		close(h.ended)
+	})
+}
+
+// Exit leaves without ending the game: it is saved to be resumed later and
+// the deputy, if there is one, takes over meanwhile.
+func (h *Host) Exit() {
+	h.endOnce.Do(func() {
+		h.Stop()
+		if h.saves != nil {
+			if path, err := h.Save(""); err != nil {
+				log.Printf("Failed to save the game: %v", err)
+			} else {
+				log.Printf("Game saved to %s", path)
+			}
+		}
+		h.mu.Lock()
+		h.networkMgr.LeaveGame()
+		h.mu.Unlock()
+		close(h.ended)
+	})
+}
+
+func (h *Host) Ended() <-chan struct{} {
+	return h.ended
+}
+
+func (h *Host) printPlayers(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "paused: %v, joining: %v\n", h.paused, h.networkMgr.CanJoin())
+	for _, player := range h.logic.GetPlayers().GetPlayers() {
+		status := "-"
+		if snake := h.logic.GetSnakeByPlayerID(player.GetId()); snake != nil {
+			status = snake.GetState().String()
+		}
+		fmt.Fprintf(w, "%10d  %-20s %-6s %5d  %s\n", player.GetId(), player.GetName(), player.GetRole(), player.GetScore(), status)
+	}
+}
+
+func (h *Host) printMetrics(w io.Writer) {
+	m := h.networkMgr.Metrics()
+	fmt.Fprintf(w, "received %d, invalid %d, spoofed steers %d, spoofed role changes %d\n",
+		m.Received, m.Invalid, m.SpoofedSteers, m.SpoofedRoleChange)
+	fmt.Fprintf(w, "rate limited %d (join %d, steer %d, discover %d)\n",
+		m.RateLimited, m.JoinsLimited, m.SteersLimited, m.DiscoversLimited)
+}
+
+const commandHelp = `commands:
+  players                  list players
+  kick <name|id> [reason]  kick a player to VIEWER
+  pause / resume           stop or restart the tick loop
+  join on|off              allow or refuse new players
+  metrics                  show received and rejected traffic
+  save [path]              save the game to resume it later
+  end                      end the game for everyone
+`
+
+// RunCommands reads admin commands line by line until "end" or EOF.
+func (h *Host) RunCommands(r io.Reader, w io.Writer) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "players":
+			h.printPlayers(w)
+		case "kick":
+			if len(fields) < 2 {
+				fmt.Fprintln(w, "usage: kick <name|id> [reason]")
+				continue
+			}
+			if err := h.Kick(fields[1], strings.Join(fields[2:], " ")); err != nil {
+				fmt.Fprintf(w, "kick failed: %v\n", err)
+			}
+		case "metrics":
+			h.printMetrics(w)
+		case "save":
+			path, err := h.Save(strings.Join(fields[1:], " "))
+			if err != nil {
+				fmt.Fprintf(w, "save failed: %v\n", err)
+				continue
+			}
+			fmt.Fprintf(w, "saved to %s\n", path)
+		case "pause":
+			h.SetPaused(true)
+		case "resume":
+			h.SetPaused(false)
+		case "join":
+			if len(fields) != 2 || fields[1] != "on" && fields[1] != "off" {
+				fmt.Fprintln(w, "usage: join on|off")
+				continue
+			}
+			h.SetCanJoin(fields[1] == "on")
+		case "end":
+			h.End()
+			fmt.Fprintln(w, network.GameEndedMessage)
+			return
+		default:
+			fmt.Fprint(w, commandHelp)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package headless
+
+import (
+	gproto "google.golang.org/protobuf/proto"
+	"log"
+	"snake-game/internal/game/interfaces"
+	"snake-game/internal/game/logic"
+	"snake-game/internal/game/match"
+	"snake-game/internal/game/saves"
+	"snake-game/internal/network"
+	proto "snake-game/internal/proto/gen"
+	"sync"
+	"time"
+)
+
+// Host runs a game without a window. mu guards the game; the network
+// manager takes it through Lock before calling back into the Host.
+type Host struct {
+	mu         sync.Mutex
+	logic      *logic.GameLogic
+	networkMgr *network.Manager
+	gameName   string
+	playerName string
+	ticker     *time.Ticker
+	closeChan  chan struct{}
+	wg         sync.WaitGroup
+	stopOnce   sync.Once
+	endOnce    sync.Once
+	observer   interfaces.GameStateListener
+	onTick     func(elapsed time.Duration)
+	paused     bool
+	ended      chan struct{}
+	master     *proto.GamePlayer
+	resumed    *saves.SavedGame
+	saves      *saves.Store
+	matchOpts  match.Options
+	match      *match.Match
+}
+
+func NewHost(gameName string, playerName string, cfg *proto.GameConfig, networkMgr *network.Manager) *Host {
+	h := &Host{
+		logic:      logic.NewGameLogic(cfg),
+		networkMgr: networkMgr,
+		gameName:   gameName,
+		playerName: playerName,
+		closeChan:  make(chan struct{}),
+		ended:      make(chan struct{}),
+	}
+	networkMgr.SetGameJoinListener(h)
+	networkMgr.SetSteerListener(h)
+	return h
+}
+
+func (h *Host) SetMap(m *logic.Map) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.logic.SetMap(m)
+}
+
+// SetMatch plays a match with these options instead of an endless game.
+func (h *Host) SetMatch(opts match.Options) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.matchOpts = opts
+}
+
+// SetSaveStore turns on saving, including the automatic save when the host
+// exits without ending the game.
+func (h *Host) SetSaveStore(store *saves.Store) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.saves = store
+}
+
+func (h *Host) SetStateObserver(observer interfaces.GameStateListener) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.observer = observer
+}
+
+// SetTickObserver reports how long each tick took to simulate and send.
+func (h *Host) SetTickObserver(onTick func(elapsed time.Duration)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onTick = onTick
+}
+
+// Resume makes Start host a saved game instead of a fresh one.
+func (h *Host) Resume(saved *saves.SavedGame) error {
+	gl, master, err := saved.Resume()
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.logic = gl
+	h.master = master
+	h.gameName = saved.Game
+	h.resumed = saved
+	return nil
+}
+
+func (h *Host) Start() {
+	h.mu.Lock()
+	canJoin := true
+	if h.resumed != nil {
+		canJoin = h.resumed.CanJoin
+		h.networkMgr.SetNextSeq(h.resumed.NextSeq)
+		h.networkMgr.RestoreSessions(h.resumed.Sessions)
+	} else {
+		h.master = h.logic.NewPlayer(h.playerName, proto.PlayerType_ROBOT, proto.NodeRole_MASTER, logic.GeneratePlayerID())
+		h.logic.AddPlayer(h.master)
+		h.logic.Init()
+	}
+	if h.matchOpts.Enabled() {
+		h.match = match.New(h.matchOpts, h.logic)
+	}
+	gameAnnounce := &proto.GameAnnouncement{
+		Config:   h.logic.Config,
+		Players:  h.logic.GetPlayers(),
+		GameName: h.gameName,
+		CanJoin:  canJoin,
+	}
+	h.networkMgr.SetGameAnnouncement(gameAnnounce)
+	h.networkMgr.ChangeRole(h.master, proto.NodeRole_MASTER)
+	h.networkMgr.SetActivityManager(gameAnnounce.Config.GetStateDelayMs())
+	h.mu.Unlock()
+	h.ticker = time.NewTicker(time.Duration(h.logic.Config.GetStateDelayMs()) * time.Millisecond)
+	h.wg.Add(1)
+	go h.run()
+}
+
+func (h *Host) run() {
+	defer h.wg.Done()
+	for {
+		select {
+		case <-h.ticker.C:
+			h.tick()
+		case <-h.closeChan:
+			return
+		}
+	}
+}
+
+func (h *Host) tick() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.onTick != nil {
+		defer func(start time.Time) { h.onTick(time.Since(start)) }(time.Now())
+	}
+	if h.paused {
+		if err := h.networkMgr.SendState(h.logic.GetState()); err != nil {
+			log.Printf("Error sending state: %v", err)
+		}
+		return
+	}
+	if h.match != nil {
+		notices, err := h.match.Step()
+		if err != nil {
+			log.Printf("Error updating game: %v", err)
+		}
+		for _, notice := range notices {
+			log.Print(notice)
+			h.networkMgr.BroadcastNotice(notice)
+		}
+	} else if err := h.logic.Update(); err != nil {
+		log.Printf("Error updating game: %v", err)
+	}
+	if err := h.networkMgr.SendState(h.logic.GetState()); err != nil {
+		log.Printf("Error sending state: %v", err)
+	}
+	if h.observer != nil {
+		h.observer.OnGameStateReceived(h.logic.GetState())
+	}
+}
+
+func (h *Host) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.closeChan)
+		if h.ticker != nil {
+			h.ticker.Stop()
+		}
+		h.wg.Wait()
+	})
+}
+
+func (h *Host) Lock() {
+	h.mu.Lock()
+}
+
+func (h *Host) Unlock() {
+	h.mu.Unlock()
+}
+
+func (h *Host) OnGameAddPlayer(player *proto.GamePlayer) {
+	h.logic.AddPlayer(player)
+}
+
+func (h *Host) GetLogic() *logic.GameLogic {
+	return h.logic
+}
+
+// Players copies the player table, so it can be read while the game runs.
+func (h *Host) Players() []*proto.GamePlayer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	players := make([]*proto.GamePlayer, 0)
+	for _, player := range h.logic.GetPlayers().GetPlayers() {
+		players = append(players, gproto.Clone(player).(*proto.GamePlayer))
+	}
+	return players
+}
+
+func (h *Host) OnSteerReceived(playerID int32, direction proto.Direction) error {
+	return h.logic.SteerSnake(playerID, direction)
+}
@@ -1,12 +1,16 @@
 package interfaces
 
 import (
+	"net"
 	"snake-game/internal/game/logic"
 	prt "snake-game/internal/proto/gen"
+	"time"
 )
 
-type GameAnnouncementListener interface {
-	OnGameAnnouncement(games []*prt.GameAnnouncement)
+type GameBrowserListener interface {
+	OnGameAdded(game *prt.GameAnnouncement, master *net.UDPAddr, latency time.Duration)
+	OnGameUpdated(game *prt.GameAnnouncement, master *net.UDPAddr, latency time.Duration)
+	OnGameRemoved(gameName string, master *net.UDPAddr)
 }
 
 type GameStateListener interface {
@@ -21,3 +25,11 @@ type GameJoinListener interface {
 type SteerListener interface {
 	OnSteerReceived(playerID int32, direction prt.Direction) error
 }
+
+type GameErrorListener interface {
+	OnErrorReceived(message string)
+}
+
+type RoleListener interface {
+	OnRoleChanged(role prt.NodeRole)
+}
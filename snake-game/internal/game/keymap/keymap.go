@@ -0,0 +1,102 @@
+// Package keymap steers by key name. The settings file binds names such as
+// "ArrowUp" or "W"; front-ends that read keys without ebiten, like the
+// terminal, resolve them here so every front-end uses the same bindings.
+//
+// Letters, digits and Space name the character typed, not a physical key,
+// so "W" follows the letter on any keyboard layout. The window resolves
+// them from its typed characters for the same reason.
+package keymap
+
+import (
+	"slices"
+	"snake-game/internal/game/config"
+	proto "snake-game/internal/proto/gen"
+	"strings"
+)
+
+type Keymap struct {
+	controls config.Controls
+}
+
+func New(c config.Controls) *Keymap {
+	return &Keymap{controls: c}
+}
+
+// Press returns the direction the key called name asks for. head is the
+// current direction of the player's snake and is only used for relative
+// steering.
+func (k *Keymap) Press(name string, head proto.Direction) (proto.Direction, bool) {
+	c := k.controls
+	if c.Relative {
+		switch {
+		case slices.Contains(c.TurnLeft, name):
+			return TurnLeft(head), true
+		case slices.Contains(c.TurnRight, name):
+			return TurnRight(head), true
+		}
+		return 0, false
+	}
+	switch {
+	case slices.Contains(c.Up, name):
+		return proto.Direction_UP, true
+	case slices.Contains(c.Down, name):
+		return proto.Direction_DOWN, true
+	case slices.Contains(c.Left, name):
+		return proto.Direction_LEFT, true
+	case slices.Contains(c.Right, name):
+		return proto.Direction_RIGHT, true
+	}
+	return 0, false
+}
+
+func TurnLeft(head proto.Direction) proto.Direction {
+	switch head {
+	case proto.Direction_UP:
+		return proto.Direction_LEFT
+	case proto.Direction_LEFT:
+		return proto.Direction_DOWN
+	case proto.Direction_DOWN:
+		return proto.Direction_RIGHT
+	default:
+		return proto.Direction_UP
+	}
+}
+
+func TurnRight(head proto.Direction) proto.Direction {
+	switch head {
+	case proto.Direction_UP:
+		return proto.Direction_RIGHT
+	case proto.Direction_RIGHT:
+		return proto.Direction_DOWN
+	case proto.Direction_DOWN:
+		return proto.Direction_LEFT
+	default:
+		return proto.Direction_UP
+	}
+}
+
+// CharName names the key that types c, as the settings file does: letters
+// by their capital, digits as "Digit1" and the space bar as "Space".
+func CharName(c rune) (string, bool) {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return strings.ToUpper(string(c)), true
+	case c >= '0' && c <= '9':
+		return "Digit" + string(c), true
+	case c == ' ':
+		return "Space", true
+	}
+	return "", false
+}
+
+// IsCharName reports whether name is bound by the character it types
+// rather than by a key's position.
+func IsCharName(name string) bool {
+	if r := []rune(name); len(r) == 1 {
+		return r[0] >= 'A' && r[0] <= 'Z'
+	}
+	if digit, ok := strings.CutPrefix(name, "Digit"); ok {
+		return len(digit) == 1 && digit[0] >= '0' && digit[0] <= '9'
+	}
+	return name == "Space"
+}
@@ -0,0 +1,16 @@
+package keymap
+
+import "testing"
+
+func TestCharNamesAreBoundByChar(t *testing.T) {
+	for c := rune(0); c < 128; c++ {
+		if name, ok := CharName(c); ok && !IsCharName(name) {
+			t.Errorf("%q types %q, which is not bound by character", c, name)
+		}
+	}
+	for _, name := range []string{"ArrowUp", "Enter", "Digit", "Digit10", "KeyW", "w"} {
+		if IsCharName(name) {
+			t.Errorf("%q is bound by character", name)
+		}
+	}
+}
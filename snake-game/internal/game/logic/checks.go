@@ -19,6 +19,13 @@ func (gl *GameLogic) isReverseDirection(current, new proto.Direction) bool {
 }
 
 func (gl *GameLogic) CanPlaceSnake() bool {
+	_, _, ok := gl.findSpawn()
+	return ok
+}
+
+// findSpawn looks for a 5x5 square free of snakes and returns a head in its
+// centre with a neighbouring tail, neither of them on food.
+func (gl *GameLogic) findSpawn() (head *proto.GameState_Coord, headDirection proto.Direction, ok bool) {
 	field := gl.GetField()
 	snakes := gl.GetSnakes()
 	for x := int32(0); x < field.Width-4; x++ {
@@ -51,23 +58,17 @@ func (gl *GameLogic) CanPlaceSnake() bool {
 			}
 			if squareEmpty {
 				headCoord := &proto.GameState_Coord{X: x + 2, Y: y + 2}
-				tailPositions := []proto.GameState_Coord{
-					{X: headCoord.X + 1, Y: headCoord.Y},
-					{X: headCoord.X - 1, Y: headCoord.Y},
-					{X: headCoord.X, Y: headCoord.Y + 1},
-					{X: headCoord.X, Y: headCoord.Y - 1},
+				if gl.isFoodAtPosition(headCoord) {
+					continue
 				}
-				for _, tailCoord := range tailPositions {
-					tailCoord.X = (tailCoord.X + field.Width) % field.Width
-					tailCoord.Y = (tailCoord.Y + field.Height) % field.Height
-					headHasFood := gl.isFoodAtPosition(headCoord)
-					tailHasFood := gl.isFoodAtPosition(&tailCoord)
-					if !headHasFood && !tailHasFood {
-						return true
+				for _, dir := range []proto.Direction{proto.Direction_LEFT, proto.Direction_RIGHT, proto.Direction_UP, proto.Direction_DOWN} {
+					tailCoord := gl.field.WrapPosition(gl.getTailPosition(headCoord, dir))
+					if !gl.isFoodAtPosition(tailCoord) {
+						return headCoord, dir, true
 					}
 				}
 			}
 		}
 	}
-	return false
+	return nil, 0, false
 }
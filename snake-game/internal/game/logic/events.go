@@ -0,0 +1,319 @@
+package logic
+
+import proto "snake-game/internal/proto/gen"
+
+type EventKind int
+
+const (
+	EventFoodEaten EventKind = iota
+	EventPlayerJoined
+	EventPlayerLeft
+	EventSnakeDied
+	EventZombieCreated
+	EventRoleChanged
+	EventScoreChanged
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventFoodEaten:
+		return "food eaten"
+	case EventPlayerJoined:
+		return "player joined"
+	case EventPlayerLeft:
+		return "player left"
+	case EventSnakeDied:
+		return "snake died"
+	case EventZombieCreated:
+		return "zombie created"
+	case EventRoleChanged:
+		return "role changed"
+	case EventScoreChanged:
+		return "score changed"
+	}
+	return "unknown"
+}
+
+type DeathCause string
+
+const (
+	DeathWall   DeathCause = "wall"
+	DeathBorder DeathCause = "border"
+	DeathSelf   DeathCause = "self"
+	DeathHeadOn DeathCause = "head-on"
+	DeathSnake  DeathCause = "snake"
+)
+
+// Event is one thing that happened between two consecutive states. Only the
+// fields that make sense for its Kind are set: KillerID and Cause for deaths,
+// the roles for role changes and the scores for score changes. A snake that
+// vanished without running into anything died of an empty Cause.
+type Event struct {
+	Kind     EventKind
+	PlayerID int32
+	KillerID int32
+	Cause    DeathCause
+	Coord    *proto.GameState_Coord
+	OldRole  proto.NodeRole
+	NewRole  proto.NodeRole
+	OldScore int32
+	NewScore int32
+}
+
+// DiffStates derives what happened between prev and next, which must be
+// consecutive states of the same game on a width x height field. Events come
+// in a fixed order: joins, role changes, food, deaths and zombies, score
+// changes, then leaves.
+func DiffStates(prev, next *proto.GameState, width, height int32) []Event {
+	if prev == nil || next == nil {
+		return nil
+	}
+	field := NewField(width, height)
+	events := make([]Event, 0)
+	events = append(events, diffPlayers(prev, next)...)
+	events = append(events, diffFoods(prev, next)...)
+	events = append(events, diffSnakes(prev, next, field)...)
+	events = append(events, diffScores(prev, next)...)
+	events = append(events, diffLeaves(prev, next)...)
+	return events
+}
+
+func playersByID(state *proto.GameState) map[int32]*proto.GamePlayer {
+	players := make(map[int32]*proto.GamePlayer)
+	for _, player := range state.GetPlayers().GetPlayers() {
+		players[player.GetId()] = player
+	}
+	return players
+}
+
+func diffPlayers(prev, next *proto.GameState) []Event {
+	var events []Event
+	before := playersByID(prev)
+	for _, player := range next.GetPlayers().GetPlayers() {
+		old, exists := before[player.GetId()]
+		switch {
+		case !exists:
+			events = append(events, Event{Kind: EventPlayerJoined, PlayerID: player.GetId(), NewRole: player.GetRole()})
+		case old.GetRole() != player.GetRole():
+			events = append(events, Event{
+				Kind:     EventRoleChanged,
+				PlayerID: player.GetId(),
+				OldRole:  old.GetRole(),
+				NewRole:  player.GetRole(),
+			})
+		}
+	}
+	return events
+}
+
+func diffScores(prev, next *proto.GameState) []Event {
+	var events []Event
+	before := playersByID(prev)
+	for _, player := range next.GetPlayers().GetPlayers() {
+		old, exists := before[player.GetId()]
+		if !exists || old.GetScore() == player.GetScore() {
+			continue
+		}
+		events = append(events, Event{
+			Kind:     EventScoreChanged,
+			PlayerID: player.GetId(),
+			OldScore: old.GetScore(),
+			NewScore: player.GetScore(),
+		})
+	}
+	return events
+}
+
+func diffLeaves(prev, next *proto.GameState) []Event {
+	var events []Event
+	after := playersByID(next)
+	for _, player := range prev.GetPlayers().GetPlayers() {
+		if _, exists := after[player.GetId()]; !exists {
+			events = append(events, Event{Kind: EventPlayerLeft, PlayerID: player.GetId(), OldRole: player.GetRole()})
+		}
+	}
+	return events
+}
+
+// diffFoods reports food that vanished under a snake's new head. Food that
+// appears, including what dead snakes drop, is not an event.
+func diffFoods(prev, next *proto.GameState) []Event {
+	var events []Event
+	remaining := make(map[coordKey]struct{}, len(next.GetFoods()))
+	for _, food := range next.GetFoods() {
+		remaining[coordKey{X: food.GetX(), Y: food.GetY()}] = struct{}{}
+	}
+	heads := make(map[coordKey]int32)
+	for _, snake := range next.GetSnakes() {
+		if IsWallSnake(snake) || len(snake.GetPoints()) == 0 {
+			continue
+		}
+		head := snake.GetPoints()[0]
+		heads[coordKey{X: head.GetX(), Y: head.GetY()}] = snake.GetPlayerId()
+	}
+	for _, food := range prev.GetFoods() {
+		key := coordKey{X: food.GetX(), Y: food.GetY()}
+		if _, stillThere := remaining[key]; stillThere {
+			continue
+		}
+		if eater, eaten := heads[key]; eaten {
+			events = append(events, Event{Kind: EventFoodEaten, PlayerID: eater, Coord: food})
+		}
+	}
+	return events
+}
+
+// diffSnakes reports every snake that stopped being ALIVE. If its new head
+// ran into something it died, otherwise its owner left it behind as a zombie.
+// A snake that is gone from next died too; where its head went is worked out
+// from where it was heading.
+func diffSnakes(prev, next *proto.GameState, field *Field) []Event {
+	var events []Event
+	vanished := make(map[int32]*proto.GameState_Coord)
+	for _, before := range prev.GetSnakes() {
+		if IsWallSnake(before) || before.GetState() != proto.GameState_Snake_ALIVE || len(before.GetPoints()) == 0 {
+			continue
+		}
+		if findSnake(next, before.GetPlayerId()) == nil {
+			vanished[before.GetPlayerId()] = field.WrapPosition(step(before.GetPoints()[0], before.GetHeadDirection()))
+		}
+	}
+	for _, before := range prev.GetSnakes() {
+		if IsWallSnake(before) || before.GetState() != proto.GameState_Snake_ALIVE || len(before.GetPoints()) == 0 {
+			continue
+		}
+		if _, ok := vanished[before.GetPlayerId()]; ok {
+			events = append(events, vanishedDeath(next, before, vanished, field))
+			continue
+		}
+		after := findSnake(next, before.GetPlayerId())
+		if after.GetState() == proto.GameState_Snake_ALIVE || len(after.GetPoints()) == 0 {
+			continue
+		}
+		head := after.GetPoints()[0]
+		cause, killerID := causeOfDeath(next, after.GetPlayerId(), head, 1)
+		if cause == "" && crossedBorder(before.GetPoints()[0], head, field) {
+			cause = DeathBorder
+		}
+		if cause == "" {
+			events = append(events, Event{Kind: EventZombieCreated, PlayerID: after.GetPlayerId(), Coord: head})
+			continue
+		}
+		events = append(events, Event{
+			Kind:     EventSnakeDied,
+			PlayerID: after.GetPlayerId(),
+			KillerID: killerID,
+			Cause:    cause,
+			Coord:    head,
+		})
+	}
+	return events
+}
+
+// vanishedDeath finds what the snake before ran into one step past its old
+// head. Its own body and the heads in vanished are gone from next, so they
+// are checked separately.
+func vanishedDeath(next *proto.GameState, before *proto.GameState_Snake, vanished map[int32]*proto.GameState_Coord, field *Field) Event {
+	body := before.GetPoints()
+	moved := step(body[0], before.GetHeadDirection())
+	head := field.WrapPosition(moved)
+	death := Event{Kind: EventSnakeDied, PlayerID: before.GetPlayerId(), Coord: head}
+	death.Cause, death.KillerID = causeOfDeath(next, before.GetPlayerId(), head, 0)
+	if death.Cause != "" {
+		return death
+	}
+	for i := 1; i < len(body)-1; i++ {
+		if samePoint(body[i], head) {
+			death.Cause, death.KillerID = DeathSelf, before.GetPlayerId()
+			return death
+		}
+	}
+	for id, other := range vanished {
+		if id != before.GetPlayerId() && samePoint(other, head) {
+			death.Cause, death.KillerID = DeathHeadOn, id
+			return death
+		}
+	}
+	if !field.IsValidPosition(moved) {
+		death.Cause = DeathBorder
+	}
+	return death
+}
+
+// causeOfDeath finds what lies under head in state, skipping the first
+// ownPoints points of the victim's own snake.
+func causeOfDeath(state *proto.GameState, victimID int32, head *proto.GameState_Coord, ownPoints int) (DeathCause, int32) {
+	for _, other := range state.GetSnakes() {
+		for i, point := range other.GetPoints() {
+			if !samePoint(point, head) {
+				continue
+			}
+			switch {
+			case IsWallSnake(other):
+				return DeathWall, 0
+			case other.GetPlayerId() == victimID:
+				if i < ownPoints {
+					continue
+				}
+				return DeathSelf, victimID
+			case i == 0:
+				return DeathHeadOn, other.GetPlayerId()
+			default:
+				return DeathSnake, other.GetPlayerId()
+			}
+		}
+	}
+	return "", 0
+}
+
+func samePoint(a, b *proto.GameState_Coord) bool {
+	return a.GetX() == b.GetX() && a.GetY() == b.GetY()
+}
+
+// step is where a head at from goes next, before any wrapping.
+func step(from *proto.GameState_Coord, direction proto.Direction) *proto.GameState_Coord {
+	to := &proto.GameState_Coord{X: from.GetX(), Y: from.GetY()}
+	switch direction {
+	case proto.Direction_UP:
+		to.Y--
+	case proto.Direction_DOWN:
+		to.Y++
+	case proto.Direction_LEFT:
+		to.X--
+	case proto.Direction_RIGHT:
+		to.X++
+	}
+	return to
+}
+
+// adjacent reports whether a and b are at most one step apart on a field
+// that wraps around.
+func adjacent(a, b *proto.GameState_Coord, field *Field) bool {
+	return wrappedDistance(a.GetX()-b.GetX(), field.Width)+wrappedDistance(a.GetY()-b.GetY(), field.Height) <= 1
+}
+
+func wrappedDistance(d, size int32) int32 {
+	d = max(d, -d)
+	if size > 0 {
+		d %= size
+		d = min(d, size-d)
+	}
+	return d
+}
+
+// crossedBorder reports a step from a to b over the edge of the field. On
+// a map that does not wrap that is a death, and the dead snake's head shows
+// up wrapped to the far side.
+func crossedBorder(a, b *proto.GameState_Coord, field *Field) bool {
+	dx, dy := a.GetX()-b.GetX(), a.GetY()-b.GetY()
+	return adjacent(a, b, field) && dx*dx+dy*dy > 1
+}
+
+func findSnake(state *proto.GameState, playerID int32) *proto.GameState_Snake {
+	for _, snake := range state.GetSnakes() {
+		if snake.GetPlayerId() == playerID {
+			return snake
+		}
+	}
+	return nil
+}
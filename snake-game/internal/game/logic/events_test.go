@@ -0,0 +1,196 @@
+package logic
+
+import (
+	proto "snake-game/internal/proto/gen"
+	"testing"
+)
+
+const fieldSize = 10
+
+func pt(x, y int32) *proto.GameState_Coord {
+	return &proto.GameState_Coord{X: x, Y: y}
+}
+
+func snake(id int32, state proto.GameState_Snake_SnakeState, dir proto.Direction, points ...*proto.GameState_Coord) *proto.GameState_Snake {
+	return &proto.GameState_Snake{PlayerId: id, State: state, HeadDirection: dir, Points: points}
+}
+
+func alive(id int32, dir proto.Direction, points ...*proto.GameState_Coord) *proto.GameState_Snake {
+	return snake(id, proto.GameState_Snake_ALIVE, dir, points...)
+}
+
+func zombie(id int32, dir proto.Direction, points ...*proto.GameState_Coord) *proto.GameState_Snake {
+	return snake(id, proto.GameState_Snake_ZOMBIE, dir, points...)
+}
+
+func wall(points ...*proto.GameState_Coord) *proto.GameState_Snake {
+	return zombie(WallPlayerIDBase, proto.Direction_LEFT, points...)
+}
+
+func players(scores map[int32]int32) *proto.GamePlayers {
+	list := make([]*proto.GamePlayer, 0)
+	for _, id := range []int32{1, 2} {
+		if score, ok := scores[id]; ok {
+			list = append(list, &proto.GamePlayer{Id: id, Score: score, Role: proto.NodeRole_NORMAL})
+		}
+	}
+	return &proto.GamePlayers{Players: list}
+}
+
+func state(order int32, scores map[int32]int32, foods []*proto.GameState_Coord, snakes ...*proto.GameState_Snake) *proto.GameState {
+	return &proto.GameState{StateOrder: order, Players: players(scores), Foods: foods, Snakes: snakes}
+}
+
+func TestDiffStates(t *testing.T) {
+	one := map[int32]int32{1: 0}
+	two := map[int32]int32{1: 0, 2: 0}
+	tests := []struct {
+		name string
+		prev *proto.GameState
+		next *proto.GameState
+		want []Event
+	}{
+		{
+			name: "move",
+			prev: state(1, one, nil, alive(1, proto.Direction_RIGHT, pt(3, 3), pt(2, 3))),
+			next: state(2, one, nil, alive(1, proto.Direction_RIGHT, pt(4, 3), pt(3, 3))),
+			want: nil,
+		},
+		{
+			name: "food eaten and growth",
+			prev: state(1, one, []*proto.GameState_Coord{pt(4, 3)}, alive(1, proto.Direction_RIGHT, pt(3, 3), pt(2, 3))),
+			next: state(2, map[int32]int32{1: 1}, nil, alive(1, proto.Direction_RIGHT, pt(4, 3), pt(3, 3), pt(2, 3))),
+			want: []Event{
+				{Kind: EventFoodEaten, PlayerID: 1, Coord: pt(4, 3)},
+				{Kind: EventScoreChanged, PlayerID: 1, OldScore: 0, NewScore: 1},
+			},
+		},
+		{
+			name: "death by wall",
+			prev: state(1, one, nil, wall(pt(5, 3)), alive(1, proto.Direction_RIGHT, pt(4, 3), pt(3, 3))),
+			next: state(2, one, nil, wall(pt(5, 3)), zombie(1, proto.Direction_RIGHT, pt(5, 3), pt(4, 3))),
+			want: []Event{{Kind: EventSnakeDied, PlayerID: 1, Cause: DeathWall, Coord: pt(5, 3)}},
+		},
+		{
+			name: "death by self",
+			prev: state(1, one, nil, alive(1, proto.Direction_DOWN, pt(3, 3), pt(4, 3), pt(4, 4), pt(3, 4), pt(2, 4))),
+			next: state(2, one, nil, zombie(1, proto.Direction_DOWN, pt(3, 4), pt(3, 3), pt(4, 3), pt(4, 4), pt(3, 4))),
+			want: []Event{{Kind: EventSnakeDied, PlayerID: 1, KillerID: 1, Cause: DeathSelf, Coord: pt(3, 4)}},
+		},
+		{
+			name: "death by another snake",
+			prev: state(1, two, nil, alive(1, proto.Direction_RIGHT, pt(3, 3), pt(2, 3)), alive(2, proto.Direction_UP, pt(4, 3), pt(4, 4), pt(4, 5))),
+			next: state(2, two, nil, zombie(1, proto.Direction_RIGHT, pt(4, 3), pt(3, 3)), alive(2, proto.Direction_UP, pt(4, 2), pt(4, 3), pt(4, 4))),
+			want: []Event{{Kind: EventSnakeDied, PlayerID: 1, KillerID: 2, Cause: DeathSnake, Coord: pt(4, 3)}},
+		},
+		{
+			name: "head-on",
+			prev: state(1, two, nil, alive(1, proto.Direction_RIGHT, pt(3, 3), pt(2, 3)), alive(2, proto.Direction_LEFT, pt(5, 3), pt(6, 3))),
+			next: state(2, two, nil, zombie(1, proto.Direction_RIGHT, pt(4, 3), pt(3, 3)), zombie(2, proto.Direction_LEFT, pt(4, 3), pt(5, 3))),
+			want: []Event{
+				{Kind: EventSnakeDied, PlayerID: 1, KillerID: 2, Cause: DeathHeadOn, Coord: pt(4, 3)},
+				{Kind: EventSnakeDied, PlayerID: 2, KillerID: 1, Cause: DeathHeadOn, Coord: pt(4, 3)},
+			},
+		},
+		{
+			name: "wrap around the edge",
+			prev: state(1, one, nil, alive(1, proto.Direction_RIGHT, pt(fieldSize-1, 3), pt(fieldSize-2, 3))),
+			next: state(2, one, nil, alive(1, proto.Direction_RIGHT, pt(0, 3), pt(fieldSize-1, 3))),
+			want: nil,
+		},
+		{
+			name: "death at the border",
+			prev: state(1, one, nil, alive(1, proto.Direction_UP, pt(3, 0), pt(3, 1))),
+			next: state(2, one, nil, zombie(1, proto.Direction_UP, pt(3, fieldSize-1), pt(3, 0))),
+			want: []Event{{Kind: EventSnakeDied, PlayerID: 1, Cause: DeathBorder, Coord: pt(3, fieldSize-1)}},
+		},
+		{
+			name: "zombie created",
+			prev: state(1, one, nil, alive(1, proto.Direction_RIGHT, pt(3, 3), pt(2, 3))),
+			next: state(2, one, nil, zombie(1, proto.Direction_RIGHT, pt(4, 3), pt(3, 3))),
+			want: []Event{{Kind: EventZombieCreated, PlayerID: 1, Coord: pt(4, 3)}},
+		},
+		{
+			name: "death at the left border",
+			prev: state(1, one, nil, alive(1, proto.Direction_LEFT, pt(0, 3), pt(1, 3))),
+			next: state(2, one, nil, zombie(1, proto.Direction_LEFT, pt(fieldSize-1, 3), pt(0, 3))),
+			want: []Event{{Kind: EventSnakeDied, PlayerID: 1, Cause: DeathBorder, Coord: pt(fieldSize-1, 3)}},
+		},
+		{
+			name: "removed snake",
+			prev: state(1, two, nil, alive(1, proto.Direction_RIGHT, pt(3, 3), pt(2, 3)), alive(2, proto.Direction_UP, pt(4, 3), pt(4, 4), pt(4, 5))),
+			next: state(2, two, nil, alive(2, proto.Direction_UP, pt(4, 2), pt(4, 3), pt(4, 4))),
+			want: []Event{{Kind: EventSnakeDied, PlayerID: 1, KillerID: 2, Cause: DeathSnake, Coord: pt(4, 3)}},
+		},
+		{
+			name: "removed snakes head-on",
+			prev: state(1, two, nil, alive(1, proto.Direction_RIGHT, pt(3, 3), pt(2, 3)), alive(2, proto.Direction_LEFT, pt(5, 3), pt(6, 3))),
+			next: state(2, two, nil),
+			want: []Event{
+				{Kind: EventSnakeDied, PlayerID: 1, KillerID: 2, Cause: DeathHeadOn, Coord: pt(4, 3)},
+				{Kind: EventSnakeDied, PlayerID: 2, KillerID: 1, Cause: DeathHeadOn, Coord: pt(4, 3)},
+			},
+		},
+		{
+			name: "removed snake over the edge",
+			prev: state(1, one, nil, alive(1, proto.Direction_DOWN, pt(3, fieldSize-1), pt(3, fieldSize-2))),
+			next: state(2, one, nil),
+			want: []Event{{Kind: EventSnakeDied, PlayerID: 1, Cause: DeathBorder, Coord: pt(3, 0)}},
+		},
+		{
+			name: "removed snake without a cause",
+			prev: state(1, one, nil, alive(1, proto.Direction_RIGHT, pt(3, 3), pt(2, 3))),
+			next: state(2, one, nil),
+			want: []Event{{Kind: EventSnakeDied, PlayerID: 1, Coord: pt(4, 3)}},
+		},
+		{
+			name: "join and leave",
+			prev: state(1, map[int32]int32{1: 4}, nil),
+			next: state(2, map[int32]int32{2: 0}, nil),
+			want: []Event{
+				{Kind: EventPlayerJoined, PlayerID: 2, NewRole: proto.NodeRole_NORMAL},
+				{Kind: EventPlayerLeft, PlayerID: 1, OldRole: proto.NodeRole_NORMAL},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffStates(tt.prev, tt.next, fieldSize, fieldSize)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d events %+v, want %+v", len(got), got, tt.want)
+			}
+			for i := range got {
+				if !sameEvent(got[i], tt.want[i]) {
+					t.Errorf("event %d is %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAdjacentWraps(t *testing.T) {
+	field := NewField(fieldSize, fieldSize)
+	tests := []struct {
+		a, b *proto.GameState_Coord
+		want bool
+	}{
+		{pt(3, 3), pt(4, 3), true},
+		{pt(3, 3), pt(3, 3), true},
+		{pt(0, 3), pt(fieldSize-1, 3), true},
+		{pt(3, fieldSize-1), pt(3, 0), true},
+		{pt(3, 3), pt(5, 3), false},
+		{pt(3, 3), pt(4, 4), false},
+		{pt(0, 0), pt(fieldSize-1, fieldSize-1), false},
+	}
+	for _, tt := range tests {
+		if got := adjacent(tt.a, tt.b, field); got != tt.want {
+			t.Errorf("adjacent(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func sameEvent(a, b Event) bool {
+	sameCoord := a.Coord == nil && b.Coord == nil || a.Coord != nil && b.Coord != nil && samePoint(a.Coord, b.Coord)
+	a.Coord, b.Coord = nil, nil
+	return sameCoord && a == b
+}
@@ -9,6 +9,11 @@ func (gl *GameLogic) GetField() *Field {
 	return gl.field
 }
 
+// NoWrap reports whether snakes die at the border instead of wrapping.
+func (gl *GameLogic) NoWrap() bool {
+	return gl.noWrap
+}
+
 func (gl *GameLogic) GetFoods() []*proto.GameState_Coord { return gl.state.Foods }
 
 func (gl *GameLogic) GetSnakes() []*proto.GameState_Snake { return gl.state.Snakes }
@@ -13,7 +13,14 @@ type GameLogic struct {
 	field         *Field
 	state         *proto.GameState
 	rnd           *rand.Rand
+	src           *rand.PCG
 	pendingSteers map[int32]proto.Direction
+	walls         map[coordKey]struct{}
+	noWrap        bool
+	outOfBounds   map[int32]bool
+	// disconnected holds players whose snake became a zombie because they
+	// dropped out rather than crashed.
+	disconnected map[int32]struct{}
 }
 
 func NewGameLogic(config *proto.GameConfig) *GameLogic {
@@ -34,12 +41,21 @@ func NewGameLogic(config *proto.GameConfig) *GameLogic {
 			Foods:      make([]*proto.GameState_Coord, 0),
 			Players:    &proto.GamePlayers{Players: make([]*proto.GamePlayer, 0)},
 		},
-		rnd:           rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0)),
 		pendingSteers: make(map[int32]proto.Direction),
+		walls:         make(map[coordKey]struct{}),
+		outOfBounds:   make(map[int32]bool),
+		disconnected:  make(map[int32]struct{}),
 	}
+	gl.Seed(uint64(time.Now().UnixNano()))
 	return gl
 }
 
+// Seed replaces the time-based random source, e.g. for simulations.
+func (gl *GameLogic) Seed(seed uint64) {
+	gl.src = rand.NewPCG(seed, 0)
+	gl.rnd = rand.New(gl.src)
+}
+
 func (gl *GameLogic) Init() {
 	gl.generateInitialFood()
 }
@@ -63,6 +79,9 @@ func (gl *GameLogic) Update() error {
 
 func (gl *GameLogic) moveSnakes() {
 	for _, snake := range gl.state.Snakes {
+		if IsWallSnake(snake) || len(snake.Points) == 0 {
+			continue
+		}
 		gl.moveSnake(snake)
 	}
 }
@@ -76,14 +95,18 @@ func (gl *GameLogic) moveSnake(snake *proto.GameState_Snake) {
 
 	switch snake.HeadDirection {
 	case proto.Direction_UP:
-		newHead.Y = (newHead.Y - 1 + gl.field.Height) % gl.field.Height
+		newHead.Y--
 	case proto.Direction_DOWN:
-		newHead.Y = (newHead.Y + 1) % gl.field.Height
+		newHead.Y++
 	case proto.Direction_LEFT:
-		newHead.X = (newHead.X - 1 + gl.field.Width) % gl.field.Width
+		newHead.X--
 	case proto.Direction_RIGHT:
-		newHead.X = (newHead.X + 1) % gl.field.Width
+		newHead.X++
 	}
+	if gl.noWrap && !gl.field.IsValidPosition(newHead) {
+		gl.outOfBounds[snake.PlayerId] = true
+	}
+	newHead = gl.field.WrapPosition(newHead)
 
 	newPoints := make([]*proto.GameState_Coord, 0, len(snake.Points)+1)
 	newPoints = append(newPoints, newHead)
@@ -109,13 +132,20 @@ func (gl *GameLogic) moveSnake(snake *proto.GameState_Snake) {
 }
 
 func (gl *GameLogic) checkCollisions() {
-	type coordKey struct{ X, Y int32 }
 	collisions := make(map[int32]bool)
+	for playerID := range gl.outOfBounds {
+		if snake := gl.GetSnakeByPlayerID(playerID); snake != nil && snake.State == proto.GameState_Snake_ZOMBIE {
+			gl.removeSnake(playerID)
+			continue
+		}
+		collisions[playerID] = true
+	}
+	gl.outOfBounds = make(map[int32]bool)
 	headCoords := make(map[coordKey]int32)
 	bodyCoords := make(map[coordKey]struct{})
 
 	for _, snake := range gl.state.Snakes {
-		if snake.State != proto.GameState_Snake_ALIVE {
+		if snake.State != proto.GameState_Snake_ALIVE || len(snake.Points) == 0 {
 			continue
 		}
 
@@ -131,6 +161,10 @@ func (gl *GameLogic) checkCollisions() {
 	}
 	collidedWith := make(map[int32][]int32)
 	for headKey, playerID := range headCoords {
+		if _, isWall := gl.walls[headKey]; isWall {
+			collisions[playerID] = true
+			continue
+		}
 		if _, exists := bodyCoords[headKey]; exists {
 			collisions[playerID] = true
 			continue
@@ -162,6 +196,9 @@ func (gl *GameLogic) checkCollisions() {
 			snake.State = proto.GameState_Snake_ZOMBIE
 
 			for _, point := range snake.Points {
+				if gl.isWallAt(point) {
+					continue
+				}
 				if gl.rnd.Float32() < 0.5 {
 					gl.state.Foods = append(gl.state.Foods, &proto.GameState_Coord{
 						X: point.X,
@@ -181,19 +218,22 @@ func (gl *GameLogic) updateFood() {
 		}
 	}
 
-	for len(gl.state.Foods) < targetFood {
+	maxAttempts := int(gl.field.Width * gl.field.Height)
+	for attempt := 0; len(gl.state.Foods) < targetFood && attempt < maxAttempts; attempt++ {
 		newFood := gl.generateFoodPosition()
 		if !gl.isPositionOccupied(newFood) {
 			gl.state.Foods = append(gl.state.Foods, newFood)
-		} else {
-			break
 		}
 	}
 }
 
 func (gl *GameLogic) generateInitialFood() {
 	for i := 0; i < int(gl.Config.FoodStatic); i++ {
-		gl.state.Foods = append(gl.state.Foods, gl.generateFoodPosition())
+		food := gl.generateFoodPosition()
+		if gl.isPositionOccupied(food) {
+			continue
+		}
+		gl.state.Foods = append(gl.state.Foods, food)
 	}
 }
 
@@ -227,12 +267,46 @@ func (gl *GameLogic) AddPlayer(player *proto.GamePlayer) {
 	}
 }
 
+func (gl *GameLogic) removeSnake(playerID int32) {
+	delete(gl.disconnected, playerID)
+	for i, snake := range gl.state.Snakes {
+		if snake.PlayerId == playerID {
+			gl.state.Snakes = append(gl.state.Snakes[:i], gl.state.Snakes[i+1:]...)
+			return
+		}
+	}
+}
+
 func (gl *GameLogic) KillPlayer(playerID int32) {
 	if snake := gl.GetSnakeByPlayerID(playerID); snake != nil {
 		snake.State = proto.GameState_Snake_ZOMBIE
 	}
 }
 
+// DisconnectPlayer turns the snake of a player who stopped answering into a
+// zombie and remembers why, so ReclaimSnake can hand it back.
+func (gl *GameLogic) DisconnectPlayer(playerID int32) {
+	if snake := gl.GetSnakeByPlayerID(playerID); snake != nil && snake.State == proto.GameState_Snake_ALIVE {
+		snake.State = proto.GameState_Snake_ZOMBIE
+		gl.disconnected[playerID] = struct{}{}
+	}
+}
+
+// ReclaimSnake brings a snake zombified by DisconnectPlayer back to life.
+// A snake that died in a collision stays dead.
+func (gl *GameLogic) ReclaimSnake(playerID int32) bool {
+	if _, ok := gl.disconnected[playerID]; !ok {
+		return false
+	}
+	delete(gl.disconnected, playerID)
+	snake := gl.GetSnakeByPlayerID(playerID)
+	if snake == nil || snake.State != proto.GameState_Snake_ZOMBIE {
+		return false
+	}
+	snake.State = proto.GameState_Snake_ALIVE
+	return true
+}
+
 func (gl *GameLogic) SteerSnake(playerID int32, direction proto.Direction) error {
 	snake := gl.GetSnakeByPlayerID(playerID)
 	if snake == nil {
@@ -11,7 +11,7 @@ func (gl *GameLogic) placeSnakes() {
 func (gl *GameLogic) placeSnake(player *proto.GamePlayer) {
 	for attempt := 0; attempt < 100; attempt++ {
 		head := gl.field.GetRandomPosition(gl.rnd)
-		if gl.isFoodAtPosition(head) {
+		if gl.isPositionOccupied(head) {
 			continue
 		}
 		directions := []proto.Direction{
@@ -28,8 +28,11 @@ func (gl *GameLogic) placeSnake(player *proto.GamePlayer) {
 		found := false
 		for _, dir := range directions {
 			tail = gl.getTailPosition(head, dir)
+			if gl.noWrap && !gl.field.IsValidPosition(tail) {
+				continue
+			}
 			tail = gl.field.WrapPosition(tail)
-			if !gl.isFoodAtPosition(tail) {
+			if !gl.isPositionOccupied(tail) {
 				selectedDirection = dir
 				found = true
 				break
@@ -59,5 +62,24 @@ func (gl *GameLogic) placeSnake(player *proto.GamePlayer) {
 		State:         proto.GameState_Snake_ALIVE,
 		HeadDirection: proto.Direction_DOWN,
 	}
-	gl.state.Snakes[player.Id] = snake
+	gl.state.Snakes = append(gl.state.Snakes, snake)
+}
+
+// SpawnSnake gives an existing player a fresh snake in a free 5x5 square,
+// replacing whatever is left of their old one. It reports false when the
+// field has no room.
+func (gl *GameLogic) SpawnSnake(playerID int32) bool {
+	head, dir, ok := gl.findSpawn()
+	if !ok {
+		return false
+	}
+	gl.removeSnake(playerID)
+	tail := gl.field.WrapPosition(gl.getTailPosition(head, dir))
+	gl.state.Snakes = append(gl.state.Snakes, &proto.GameState_Snake{
+		PlayerId:      playerID,
+		Points:        []*proto.GameState_Coord{head, tail},
+		State:         proto.GameState_Snake_ALIVE,
+		HeadDirection: dir,
+	})
+	return true
 }
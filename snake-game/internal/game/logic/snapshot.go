@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"fmt"
+	gproto "google.golang.org/protobuf/proto"
+	"math/rand/v2"
+	proto "snake-game/internal/proto/gen"
+)
+
+// Snapshot is a deep copy of a running simulation, enough to carry on with
+// it later exactly where it stopped.
+type Snapshot struct {
+	Config *proto.GameConfig
+	State  *proto.GameState
+	Rand   []byte
+	NoWrap bool
+}
+
+func (gl *GameLogic) Snapshot() (*Snapshot, error) {
+	rnd, err := gl.src.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("saving random state: %v", err)
+	}
+	return &Snapshot{
+		Config: gproto.Clone(gl.Config).(*proto.GameConfig),
+		State:  gproto.Clone(gl.state).(*proto.GameState),
+		Rand:   rnd,
+		NoWrap: gl.noWrap,
+	}, nil
+}
+
+// Restore builds a GameLogic that continues from snap.
+func Restore(snap *Snapshot) (*GameLogic, error) {
+	gl := NewGameLogic(snap.Config)
+	if len(snap.Rand) > 0 {
+		src := &rand.PCG{}
+		if err := src.UnmarshalBinary(snap.Rand); err != nil {
+			return nil, fmt.Errorf("restoring random state: %v", err)
+		}
+		gl.src = src
+		gl.rnd = rand.New(src)
+	}
+	gl.state = snap.State
+	if gl.state.Players == nil {
+		gl.state.Players = &proto.GamePlayers{}
+	}
+	gl.Resume(snap.NoWrap)
+	return gl, nil
+}
@@ -0,0 +1,81 @@
+package logic
+
+import (
+	proto "snake-game/internal/proto/gen"
+)
+
+const WallPlayerIDBase int32 = -1
+
+type Map struct {
+	Name   string
+	Width  int32
+	Height int32
+	Walls  []*proto.GameState_Coord
+	NoWrap bool
+}
+
+type coordKey struct{ X, Y int32 }
+
+func IsWallSnake(snake *proto.GameState_Snake) bool {
+	return snake.GetPlayerId() <= WallPlayerIDBase
+}
+
+func (gl *GameLogic) SetMap(m *Map) {
+	gl.Config.Width = m.Width
+	gl.Config.Height = m.Height
+	gl.field = NewField(m.Width, m.Height)
+	gl.noWrap = m.NoWrap
+	gl.walls = make(map[coordKey]struct{}, len(m.Walls))
+	for _, wall := range m.Walls {
+		gl.walls[coordKey{X: wall.X, Y: wall.Y}] = struct{}{}
+	}
+	gl.state.Snakes = append(gl.state.Snakes, gl.wallSnakes()...)
+}
+
+func (gl *GameLogic) wallSnakes() []*proto.GameState_Snake {
+	snakes := make([]*proto.GameState_Snake, 0)
+	nextID := WallPlayerIDBase
+	for y := int32(0); y < gl.field.Height; y++ {
+		var run []*proto.GameState_Coord
+		for x := int32(0); x <= gl.field.Width; x++ {
+			if _, isWall := gl.walls[coordKey{X: x, Y: y}]; isWall && x < gl.field.Width {
+				run = append(run, &proto.GameState_Coord{X: x, Y: y})
+				continue
+			}
+			if len(run) > 0 {
+				snakes = append(snakes, &proto.GameState_Snake{
+					PlayerId:      nextID,
+					Points:        run,
+					State:         proto.GameState_Snake_ZOMBIE,
+					HeadDirection: proto.Direction_LEFT,
+				})
+				nextID--
+				run = nil
+			}
+		}
+	}
+	return snakes
+}
+
+func (gl *GameLogic) isWallAt(coord *proto.GameState_Coord) bool {
+	_, isWall := gl.walls[coordKey{X: coord.X, Y: coord.Y}]
+	return isWall
+}
+
+// Resume takes over a state received from the old master: walls only travel
+// as wall snakes, whether the map wraps comes from the old master separately,
+// and nothing queued for the old master's next tick applies.
+func (gl *GameLogic) Resume(noWrap bool) {
+	gl.noWrap = noWrap
+	gl.walls = make(map[coordKey]struct{})
+	for _, snake := range gl.state.Snakes {
+		if !IsWallSnake(snake) {
+			continue
+		}
+		for _, point := range snake.Points {
+			gl.walls[coordKey{X: point.X, Y: point.Y}] = struct{}{}
+		}
+	}
+	gl.pendingSteers = make(map[int32]proto.Direction)
+	gl.outOfBounds = make(map[int32]bool)
+}
@@ -0,0 +1,112 @@
+package logic
+
+import (
+	gproto "google.golang.org/protobuf/proto"
+	proto "snake-game/internal/proto/gen"
+)
+
+// The simulation keeps every cell of a snake as an absolute coordinate. On
+// the wire a snake is its head followed by key points, each an offset from
+// the point before it along one axis, so a straight run of cells is a
+// single offset. EncodeState and DecodeState convert between the two; they
+// are applied where states leave or enter the process and nowhere else.
+
+// EncodeState returns a copy of state with every snake body turned into
+// key-point offsets, none of them a whole side of the field long. A step across the border of the torus is encoded as
+// the single step it is in the game, not as a jump across the field.
+func EncodeState(state *proto.GameState, width, height int32) *proto.GameState {
+	encoded := gproto.Clone(state).(*proto.GameState)
+	for _, snake := range encoded.Snakes {
+		if len(snake.Points) == 0 {
+			continue
+		}
+		points := []*proto.GameState_Coord{snake.Points[0]}
+		var last *proto.GameState_Coord
+		for i := 1; i < len(snake.Points); i++ {
+			step := &proto.GameState_Coord{
+				X: shortestStep(snake.Points[i].X-snake.Points[i-1].X, width),
+				Y: shortestStep(snake.Points[i].Y-snake.Points[i-1].Y, height),
+			}
+			if last != nil && sameAxis(last, step) && abs(last.X+step.X) < width && abs(last.Y+step.Y) < height {
+				last.X += step.X
+				last.Y += step.Y
+				continue
+			}
+			last = step
+			points = append(points, step)
+		}
+		snake.Points = points
+	}
+	return encoded
+}
+
+// DecodeState reverses EncodeState, expanding every offset into the cells
+// it covers. The offsets must already have been checked with IsKeyOffset.
+func DecodeState(state *proto.GameState, width, height int32) *proto.GameState {
+	decoded := gproto.Clone(state).(*proto.GameState)
+	for _, snake := range decoded.Snakes {
+		if len(snake.Points) == 0 {
+			continue
+		}
+		cells := []*proto.GameState_Coord{snake.Points[0]}
+		for _, offset := range snake.Points[1:] {
+			stepX, stepY := sign(offset.X), sign(offset.Y)
+			for n := abs(offset.X) + abs(offset.Y); n > 0; n-- {
+				prev := cells[len(cells)-1]
+				cells = append(cells, &proto.GameState_Coord{
+					X: mod(prev.X+stepX, width),
+					Y: mod(prev.Y+stepY, height),
+				})
+			}
+		}
+		snake.Points = cells
+	}
+	return decoded
+}
+
+// IsKeyOffset reports whether offset runs along one axis for at least one
+// and less than a whole side of the field.
+func IsKeyOffset(offset *proto.GameState_Coord, width, height int32) bool {
+	x, y := offset.GetX(), offset.GetY()
+	return (x == 0) != (y == 0) && abs(x) < width && abs(y) < height
+}
+
+// OffsetLength is the number of cells offset covers.
+func OffsetLength(offset *proto.GameState_Coord) int {
+	return int(abs(offset.GetX())) + int(abs(offset.GetY()))
+}
+
+func sameAxis(a, b *proto.GameState_Coord) bool {
+	return sign(a.X) == sign(b.X) && sign(a.Y) == sign(b.Y)
+}
+
+func shortestStep(delta, size int32) int32 {
+	switch {
+	case delta > size/2:
+		return delta - size
+	case delta < -size/2:
+		return delta + size
+	}
+	return delta
+}
+
+func sign(v int32) int32 {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+func abs(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func mod(v, size int32) int32 {
+	return (v%size + size) % size
+}
@@ -0,0 +1,139 @@
+package maps
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"snake-game/internal/game/logic"
+	proto "snake-game/internal/proto/gen"
+	"sort"
+	"strings"
+)
+
+const (
+	wallCell    = '#'
+	noWrapFlag  = "!nowrap"
+	minFieldDim = 10
+	maxFieldDim = 100
+)
+
+//go:embed bundled/*.txt
+var bundled embed.FS
+
+type yamlMap struct {
+	Name   string   `yaml:"name"`
+	Width  int32    `yaml:"width"`
+	Height int32    `yaml:"height"`
+	NoWrap bool     `yaml:"nowrap"`
+	Rows   []string `yaml:"rows"`
+	Walls  []struct {
+		X int32 `yaml:"x"`
+		Y int32 `yaml:"y"`
+	} `yaml:"walls"`
+}
+
+func Bundled() []string {
+	entries, err := bundled.ReadDir("bundled")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".txt"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Load(nameOrPath string) (*logic.Map, error) {
+	if data, err := bundled.ReadFile("bundled/" + nameOrPath + ".txt"); err == nil {
+		return ParseASCII(nameOrPath, string(data))
+	}
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("map %q is neither bundled nor readable: %v", nameOrPath, err)
+	}
+	name := strings.TrimSuffix(filepath.Base(nameOrPath), filepath.Ext(nameOrPath))
+	switch strings.ToLower(filepath.Ext(nameOrPath)) {
+	case ".yaml", ".yml":
+		return ParseYAML(name, data)
+	default:
+		return ParseASCII(name, string(data))
+	}
+}
+
+func ParseASCII(name string, text string) (*logic.Map, error) {
+	m := &logic.Map{Name: name}
+	rows := make([]string, 0)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		switch {
+		case line == "":
+			continue
+		case strings.EqualFold(line, noWrapFlag):
+			m.NoWrap = true
+		default:
+			rows = append(rows, line)
+		}
+	}
+	if err := fillFromRows(m, rows); err != nil {
+		return nil, err
+	}
+	return m, validate(m)
+}
+
+func ParseYAML(name string, data []byte) (*logic.Map, error) {
+	var ym yamlMap
+	if err := yaml.Unmarshal(data, &ym); err != nil {
+		return nil, fmt.Errorf("parsing map %q: %v", name, err)
+	}
+	m := &logic.Map{Name: name, NoWrap: ym.NoWrap, Width: ym.Width, Height: ym.Height}
+	if ym.Name != "" {
+		m.Name = ym.Name
+	}
+	if len(ym.Rows) > 0 {
+		if err := fillFromRows(m, ym.Rows); err != nil {
+			return nil, err
+		}
+	}
+	for _, wall := range ym.Walls {
+		m.Walls = append(m.Walls, &proto.GameState_Coord{X: wall.X, Y: wall.Y})
+	}
+	return m, validate(m)
+}
+
+func fillFromRows(m *logic.Map, rows []string) error {
+	if len(rows) == 0 {
+		return fmt.Errorf("map %q has no rows", m.Name)
+	}
+	m.Height = int32(len(rows))
+	m.Width = int32(len(rows[0]))
+	for y, row := range rows {
+		if int32(len(row)) != m.Width {
+			return fmt.Errorf("map %q row %d has width %d, expected %d", m.Name, y, len(row), m.Width)
+		}
+		for x, cell := range row {
+			if cell == wallCell {
+				m.Walls = append(m.Walls, &proto.GameState_Coord{X: int32(x), Y: int32(y)})
+			}
+		}
+	}
+	return nil
+}
+
+func validate(m *logic.Map) error {
+	if m.Width < minFieldDim || m.Width > maxFieldDim || m.Height < minFieldDim || m.Height > maxFieldDim {
+		return fmt.Errorf("map %q is %dx%d, field sides must be between %d and %d",
+			m.Name, m.Width, m.Height, minFieldDim, maxFieldDim)
+	}
+	for _, wall := range m.Walls {
+		if wall.X < 0 || wall.X >= m.Width || wall.Y < 0 || wall.Y >= m.Height {
+			return fmt.Errorf("map %q has wall (%d, %d) outside the field", m.Name, wall.X, wall.Y)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package match
+
+import (
+	"fmt"
+	"snake-game/internal/game/logic"
+	proto "snake-game/internal/proto/gen"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultCountdown = 3 * time.Second
+	// ResultsPrefix starts the notice with the final standings, so clients
+	// can tell it from the other match notices.
+	ResultsPrefix = "Match over"
+)
+
+// Options configure match mode. A match is one or more rounds; a round ends
+// when its time is up or at most one snake is left, and the match ends after
+// the last round or as soon as someone reaches the score target.
+type Options struct {
+	Rounds      int
+	Duration    time.Duration
+	ScoreTarget int32
+	Countdown   time.Duration
+}
+
+func (o Options) Enabled() bool {
+	return o.Rounds > 0 || o.Duration > 0 || o.ScoreTarget > 0
+}
+
+type phase int
+
+const (
+	countdown phase = iota
+	playing
+	finished
+)
+
+// Match drives a GameLogic through the rounds of a match. The master calls
+// Step once per tick instead of GameLogic.Update.
+type Match struct {
+	opts      Options
+	logic     *logic.GameLogic
+	tick      time.Duration
+	phase     phase
+	round     int
+	ticksLeft int
+	announced int
+	results   string
+}
+
+func New(opts Options, gl *logic.GameLogic) *Match {
+	if opts.Rounds <= 0 {
+		opts.Rounds = 1
+	}
+	tick := time.Duration(gl.Config.GetStateDelayMs()) * time.Millisecond
+	if tick <= 0 {
+		tick = time.Second
+	}
+	m := &Match{opts: opts, logic: gl, tick: tick, round: 1}
+	m.startCountdown()
+	return m
+}
+
+func (m *Match) ticks(d time.Duration) int {
+	return int((d + m.tick - 1) / m.tick)
+}
+
+func (m *Match) startCountdown() {
+	m.phase = countdown
+	m.ticksLeft = m.ticks(m.opts.Countdown)
+	m.announced = -1
+}
+
+// Step advances the match by one tick and returns what the players should
+// be told about it.
+func (m *Match) Step() ([]string, error) {
+	switch m.phase {
+	case countdown:
+		return m.stepCountdown(), nil
+	case playing:
+		if err := m.logic.Update(); err != nil {
+			return nil, err
+		}
+		return m.stepPlaying(), nil
+	}
+	return nil, nil
+}
+
+func (m *Match) stepCountdown() []string {
+	var notices []string
+	if m.ticksLeft > 0 {
+		seconds := int((time.Duration(m.ticksLeft)*m.tick + time.Second - 1) / time.Second)
+		if seconds != m.announced {
+			m.announced = seconds
+			notices = append(notices, fmt.Sprintf("Round %d/%d starts in %d", m.round, m.opts.Rounds, seconds))
+		}
+		m.ticksLeft--
+		return notices
+	}
+	m.phase = playing
+	m.ticksLeft = m.ticks(m.opts.Duration)
+	return append(notices, fmt.Sprintf("Round %d/%d: go!", m.round, m.opts.Rounds))
+}
+
+func (m *Match) stepPlaying() []string {
+	if m.opts.ScoreTarget > 0 {
+		if leaders, score := m.leaders(); score >= m.opts.ScoreTarget {
+			return m.finish(leaders, score)
+		}
+	}
+	timeUp := false
+	if m.opts.Duration > 0 {
+		m.ticksLeft--
+		timeUp = m.ticksLeft <= 0
+	}
+	if !timeUp && !m.roundOver() {
+		return nil
+	}
+	leaders, score := m.leaders()
+	if m.round >= m.opts.Rounds {
+		return m.finish(leaders, score)
+	}
+	notice := fmt.Sprintf("Round %d over, leading: %s with %d", m.round, strings.Join(leaders, ", "), score)
+	m.round++
+	m.respawn()
+	m.startCountdown()
+	return append([]string{notice}, m.stepCountdown()...)
+}
+
+// roundOver is true once at most one of several players, or nobody at all,
+// still has a live snake.
+func (m *Match) roundOver() bool {
+	inPlay, alive := 0, 0
+	for _, player := range m.logic.GetPlayers().GetPlayers() {
+		if player.GetRole() == proto.NodeRole_VIEWER {
+			continue
+		}
+		inPlay++
+		if snake := m.logic.GetSnakeByPlayerID(player.GetId()); snake != nil && snake.GetState() == proto.GameState_Snake_ALIVE {
+			alive++
+		}
+	}
+	if inPlay > 1 {
+		return alive <= 1
+	}
+	return alive == 0
+}
+
+// respawn gives every player whose snake died a new one for the next round.
+func (m *Match) respawn() {
+	for _, player := range m.logic.GetPlayers().GetPlayers() {
+		if player.GetRole() == proto.NodeRole_VIEWER {
+			continue
+		}
+		if snake := m.logic.GetSnakeByPlayerID(player.GetId()); snake != nil && snake.GetState() == proto.GameState_Snake_ALIVE {
+			continue
+		}
+		m.logic.SpawnSnake(player.GetId())
+	}
+}
+
+func (m *Match) leaders() ([]string, int32) {
+	var names []string
+	best := int32(-1)
+	for _, player := range m.logic.GetPlayers().GetPlayers() {
+		switch {
+		case player.GetScore() > best:
+			best = player.GetScore()
+			names = []string{player.GetName()}
+		case player.GetScore() == best:
+			names = append(names, player.GetName())
+		}
+	}
+	return names, max(best, 0)
+}
+
+func (m *Match) finish(leaders []string, score int32) []string {
+	m.phase = finished
+	var sb strings.Builder
+	if len(leaders) == 1 {
+		fmt.Fprintf(&sb, "%s: %s wins with %d", ResultsPrefix, leaders[0], score)
+	} else {
+		fmt.Fprintf(&sb, "%s: draw between %s with %d", ResultsPrefix, strings.Join(leaders, ", "), score)
+	}
+	players := append([]*proto.GamePlayer(nil), m.logic.GetPlayers().GetPlayers()...)
+	sort.SliceStable(players, func(i, j int) bool { return players[i].GetScore() > players[j].GetScore() })
+	for i, player := range players {
+		fmt.Fprintf(&sb, "\n%d. %s %d", i+1, player.GetName(), player.GetScore())
+	}
+	m.results = sb.String()
+	return []string{m.results}
+}
+
+func (m *Match) Finished() bool {
+	return m.phase == finished
+}
+
+func (m *Match) Results() string {
+	return m.results
+}
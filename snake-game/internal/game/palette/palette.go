@@ -0,0 +1,19 @@
+package palette
+
+import (
+	"golang.org/x/image/colornames"
+	"image/color"
+)
+
+var (
+	Background = color.RGBA{R: 0x1a, G: 0x1f, B: 0x2d, A: 0xff}
+	Cell       = color.RGBA{R: 0x2d, G: 0x32, B: 0x45, A: 0xff}
+	Food       = colornames.Red
+	Wall       = colornames.Slategray
+	Head       = colornames.Green
+	Body       = colornames.Hotpink
+)
+
+func All() color.Palette {
+	return color.Palette{Background, Cell, Food, Wall, Head, Body}
+}
@@ -0,0 +1,208 @@
+package saves
+
+import (
+	"encoding/json"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"os"
+	"path/filepath"
+	"snake-game/internal/game/config"
+	"snake-game/internal/game/logic"
+	"snake-game/internal/network"
+	proto "snake-game/internal/proto/gen"
+	"sort"
+	"strings"
+	"time"
+)
+
+const savesDir = "saves"
+
+// SavedGame is everything a master needs to host a game again: the
+// simulation, who the master was and the rejoin tokens it handed out.
+type SavedGame struct {
+	Game     string                  `json:"game"`
+	Saved    time.Time               `json:"saved"`
+	MasterID int32                   `json:"master_id"`
+	CanJoin  bool                    `json:"can_join"`
+	NextSeq  int64                   `json:"next_seq"`
+	Config   json.RawMessage         `json:"config"`
+	State    json.RawMessage         `json:"state"`
+	Rand     []byte                  `json:"rand"`
+	NoWrap   bool                    `json:"no_wrap"`
+	Sessions []network.SessionRecord `json:"sessions"`
+}
+
+func New(game string, masterID int32, snap *logic.Snapshot) (*SavedGame, error) {
+	cfg, err := protojson.Marshal(snap.Config)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling config: %v", err)
+	}
+	state, err := protojson.Marshal(snap.State)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling state: %v", err)
+	}
+	return &SavedGame{
+		Game:     game,
+		Saved:    time.Now(),
+		MasterID: masterID,
+		Config:   cfg,
+		State:    state,
+		Rand:     snap.Rand,
+		NoWrap:   snap.NoWrap,
+	}, nil
+}
+
+// Capture saves the game a master is currently hosting.
+func Capture(game string, masterID int32, gl *logic.GameLogic, mgr *network.Manager) (*SavedGame, error) {
+	snap, err := gl.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := New(game, masterID, snap)
+	if err != nil {
+		return nil, err
+	}
+	saved.CanJoin = mgr.CanJoin()
+	saved.NextSeq = mgr.NextSeq()
+	saved.Sessions = mgr.Sessions()
+	return saved, nil
+}
+
+// Resume rebuilds the saved simulation and finds the master's own player in
+// it.
+func (g *SavedGame) Resume() (*logic.GameLogic, *proto.GamePlayer, error) {
+	snap, err := g.Snapshot()
+	if err != nil {
+		return nil, nil, err
+	}
+	gl, err := logic.Restore(snap)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, player := range gl.GetPlayers().GetPlayers() {
+		if player.GetId() == g.MasterID {
+			return gl, player, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("saved game has no player %d", g.MasterID)
+}
+
+func (g *SavedGame) Snapshot() (*logic.Snapshot, error) {
+	snap := &logic.Snapshot{
+		Config: &proto.GameConfig{},
+		State:  &proto.GameState{},
+		Rand:   g.Rand,
+		NoWrap: g.NoWrap,
+	}
+	if err := protojson.Unmarshal(g.Config, snap.Config); err != nil {
+		return nil, fmt.Errorf("parsing config: %v", err)
+	}
+	if err := protojson.Unmarshal(g.State, snap.State); err != nil {
+		return nil, fmt.Errorf("parsing state: %v", err)
+	}
+	return snap, nil
+}
+
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func DefaultStore() (*Store, error) {
+	dir, err := config.UserDir()
+	if err != nil {
+		return nil, fmt.Errorf("locating config directory: %v", err)
+	}
+	return NewStore(filepath.Join(dir, savesDir)), nil
+}
+
+// Path is where the save for game lives. Game names come from the network,
+// so they are always turned into a file name inside the store.
+func (s *Store) Path(game string) string {
+	return filepath.Join(s.dir, fileName(game)+".json")
+}
+
+func fileName(game string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, game)
+}
+
+// Save writes the game to path, which only the user gives, or to the store
+// when path is empty, and returns where it went.
+func (s *Store) Save(game *SavedGame, path string) (string, error) {
+	if path == "" {
+		path = s.Path(game.Game)
+	}
+	data, err := json.MarshalIndent(game, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshaling saved game: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("creating saves directory: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("writing saved game: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", fmt.Errorf("writing saved game: %v", err)
+	}
+	return path, nil
+}
+
+// Delete removes the save for game, if there is one.
+func (s *Store) Delete(game string) error {
+	if err := os.Remove(s.Path(game)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("deleting saved game: %v", err)
+	}
+	return nil
+}
+
+// Load reads the saved game called game from the store.
+func (s *Store) Load(game string) (*SavedGame, error) {
+	return LoadFile(s.Path(game))
+}
+
+// LoadFile reads a saved game from a file the user named.
+func LoadFile(path string) (*SavedGame, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading saved game: %v", err)
+	}
+	var saved SavedGame
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("parsing saved game: %v", err)
+	}
+	return &saved, nil
+}
+
+// List returns the saved games in the store, newest first.
+func (s *Store) List() ([]*SavedGame, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing saved games: %v", err)
+	}
+	games := make([]*SavedGame, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		saved, err := LoadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		games = append(games, saved)
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].Saved.After(games[j].Saved) })
+	return games, nil
+}
@@ -0,0 +1,17 @@
+package saves
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPathStaysInStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	for _, game := range []string{"lab", "a/b", `a\b`, "../x", "..", "x.json", "/etc/passwd"} {
+		path := store.Path(game)
+		if filepath.Dir(path) != dir {
+			t.Errorf("Path(%q) = %s, outside %s", game, path, dir)
+		}
+	}
+}
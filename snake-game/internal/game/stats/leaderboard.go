@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type LeaderboardEntry struct {
+	Name       string
+	Games      int
+	Wins       int
+	BestScore  int32
+	TotalScore int32
+	Kills      int
+	MaxLength  int
+}
+
+type PlayerMatch struct {
+	Match  *MatchSummary
+	Player *PlayerSummary
+	Rank   int
+}
+
+func (c ConfigSummary) Key() string {
+	return fmt.Sprintf("%dx%d food %d delay %dms", c.Width, c.Height, c.FoodStatic, c.StateDelayMs)
+}
+
+func AllTime(matches []*MatchSummary) []*LeaderboardEntry {
+	entries := make(map[string]*LeaderboardEntry)
+	for _, match := range matches {
+		winner := winnerOf(match)
+		for _, player := range match.Players {
+			entry, exists := entries[player.Name]
+			if !exists {
+				entry = &LeaderboardEntry{Name: player.Name}
+				entries[player.Name] = entry
+			}
+			entry.Games++
+			if player == winner {
+				entry.Wins++
+			}
+			entry.TotalScore += player.Score
+			entry.Kills += player.Kills
+			if player.Score > entry.BestScore {
+				entry.BestScore = player.Score
+			}
+			if player.MaxLength > entry.MaxLength {
+				entry.MaxLength = player.MaxLength
+			}
+		}
+	}
+	result := make([]*LeaderboardEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].BestScore != result[j].BestScore {
+			return result[i].BestScore > result[j].BestScore
+		}
+		if result[i].Wins != result[j].Wins {
+			return result[i].Wins > result[j].Wins
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func ByConfig(matches []*MatchSummary) map[string][]*LeaderboardEntry {
+	grouped := make(map[string][]*MatchSummary)
+	for _, match := range matches {
+		key := match.Config.Key()
+		grouped[key] = append(grouped[key], match)
+	}
+	result := make(map[string][]*LeaderboardEntry, len(grouped))
+	for key, group := range grouped {
+		result[key] = AllTime(group)
+	}
+	return result
+}
+
+func ForPlayer(matches []*MatchSummary, name string) []*PlayerMatch {
+	result := make([]*PlayerMatch, 0)
+	for _, match := range matches {
+		ranked := rankPlayers(match)
+		for i, player := range ranked {
+			if strings.EqualFold(player.Name, name) {
+				result = append(result, &PlayerMatch{Match: match, Player: player, Rank: i + 1})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Match.EndedAt.After(result[j].Match.EndedAt)
+	})
+	return result
+}
+
+func rankPlayers(match *MatchSummary) []*PlayerSummary {
+	ranked := make([]*PlayerSummary, len(match.Players))
+	copy(ranked, match.Players)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
+
+func winnerOf(match *MatchSummary) *PlayerSummary {
+	ranked := rankPlayers(match)
+	if len(ranked) == 0 {
+		return nil
+	}
+	return ranked[0]
+}
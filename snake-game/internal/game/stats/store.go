@@ -0,0 +1,72 @@
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"snake-game/internal/game/config"
+	"sync"
+)
+
+const matchesFile = "matches.jsonl"
+
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+func DefaultStore() (*Store, error) {
+	dir, err := config.UserDir()
+	if err != nil {
+		return nil, fmt.Errorf("locating config directory: %v", err)
+	}
+	return NewStore(filepath.Join(dir, matchesFile)), nil
+}
+
+func (s *Store) Append(match *MatchSummary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := json.Marshal(match)
+	if err != nil {
+		return fmt.Errorf("marshaling match summary: %v", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening match store: %v", err)
+	}
+	defer file.Close()
+	if _, err = file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing match summary: %v", err)
+	}
+	return nil
+}
+
+func (s *Store) Load() ([]*MatchSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening match store: %v", err)
+	}
+	defer file.Close()
+	matches := make([]*MatchSummary, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var match MatchSummary
+		if err := json.Unmarshal(scanner.Bytes(), &match); err != nil {
+			continue
+		}
+		matches = append(matches, &match)
+	}
+	return matches, scanner.Err()
+}
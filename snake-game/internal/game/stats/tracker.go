@@ -0,0 +1,164 @@
+package stats
+
+import (
+	gproto "google.golang.org/protobuf/proto"
+	"snake-game/internal/game/logic"
+	proto "snake-game/internal/proto/gen"
+	"sync"
+	"time"
+)
+
+const (
+	CauseWall     = "wall"
+	CauseSelf     = "self"
+	CauseHeadOn   = "head-on"
+	CauseSnake    = "snake"
+	CauseBorder   = "border"
+	CauseLeft     = "left"
+	CauseSurvived = ""
+)
+
+type PlayerSummary struct {
+	ID        int32  `json:"id"`
+	Name      string `json:"name"`
+	Score     int32  `json:"score"`
+	MaxLength int    `json:"max_length"`
+	Kills     int    `json:"kills"`
+	Ticks     int32  `json:"ticks"`
+	Cause     string `json:"cause_of_death,omitempty"`
+	KilledBy  string `json:"killed_by,omitempty"`
+}
+
+type ConfigSummary struct {
+	Width        int32 `json:"width"`
+	Height       int32 `json:"height"`
+	FoodStatic   int32 `json:"food_static"`
+	StateDelayMs int32 `json:"state_delay_ms"`
+}
+
+type MatchSummary struct {
+	GameName  string           `json:"game_name"`
+	Config    ConfigSummary    `json:"config"`
+	StartedAt time.Time        `json:"started_at"`
+	EndedAt   time.Time        `json:"ended_at"`
+	Duration  time.Duration    `json:"duration"`
+	Players   []*PlayerSummary `json:"players"`
+}
+
+type Tracker struct {
+	mu        sync.Mutex
+	gameName  string
+	config    ConfigSummary
+	startedAt time.Time
+	prev      *proto.GameState
+	players   map[int32]*PlayerSummary
+	joinedAt  map[int32]int32
+	order     []int32
+}
+
+func NewTracker(gameName string, cfg *proto.GameConfig) *Tracker {
+	return &Tracker{
+		gameName: gameName,
+		config: ConfigSummary{
+			Width:        cfg.GetWidth(),
+			Height:       cfg.GetHeight(),
+			FoodStatic:   cfg.GetFoodStatic(),
+			StateDelayMs: cfg.GetStateDelayMs(),
+		},
+		startedAt: time.Now(),
+		players:   make(map[int32]*PlayerSummary),
+		joinedAt:  make(map[int32]int32),
+	}
+}
+
+func (t *Tracker) Observe(state *proto.GameState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if state == nil || (t.prev != nil && state.GetStateOrder() <= t.prev.GetStateOrder()) {
+		return
+	}
+	for _, player := range state.GetPlayers().GetPlayers() {
+		summary := t.player(player.GetId(), state.GetStateOrder())
+		summary.Name = player.GetName()
+		summary.Score = player.GetScore()
+	}
+	for _, snake := range state.GetSnakes() {
+		if logic.IsWallSnake(snake) {
+			continue
+		}
+		summary := t.player(snake.GetPlayerId(), state.GetStateOrder())
+		if snake.GetState() == proto.GameState_Snake_ALIVE {
+			summary.Ticks = state.GetStateOrder() - t.joinedAt[snake.GetPlayerId()]
+		}
+		if length := len(snake.GetPoints()); length > summary.MaxLength {
+			summary.MaxLength = length
+		}
+	}
+	if t.prev != nil {
+		t.attributeDeaths(t.prev, state)
+	}
+	t.prev = gproto.Clone(state).(*proto.GameState)
+}
+
+func (t *Tracker) player(id int32, stateOrder int32) *PlayerSummary {
+	summary, exists := t.players[id]
+	if !exists {
+		summary = &PlayerSummary{ID: id}
+		t.players[id] = summary
+		t.joinedAt[id] = stateOrder
+		t.order = append(t.order, id)
+	}
+	return summary
+}
+
+func (t *Tracker) attributeDeaths(prev, cur *proto.GameState) {
+	for _, event := range logic.DiffStates(prev, cur, t.config.Width, t.config.Height) {
+		if event.Kind != logic.EventSnakeDied && event.Kind != logic.EventZombieCreated {
+			continue
+		}
+		victim := t.players[event.PlayerID]
+		if victim == nil || victim.Cause != CauseSurvived {
+			continue
+		}
+		victim.Cause = string(event.Cause)
+		if event.Kind == logic.EventZombieCreated || event.Cause == "" {
+			victim.Cause = CauseLeft
+		}
+		switch event.Cause {
+		case logic.DeathHeadOn:
+			victim.KilledBy = t.nameOf(event.KillerID)
+		case logic.DeathSnake:
+			victim.KilledBy = t.nameOf(event.KillerID)
+			if killer := t.players[event.KillerID]; killer != nil {
+				killer.Kills++
+			}
+		}
+	}
+}
+
+func (t *Tracker) nameOf(id int32) string {
+	if summary := t.players[id]; summary != nil {
+		return summary.Name
+	}
+	return ""
+}
+
+func (t *Tracker) Finish() *MatchSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	summary := &MatchSummary{
+		GameName:  t.gameName,
+		Config:    t.config,
+		StartedAt: t.startedAt,
+		EndedAt:   now,
+		Duration:  now.Sub(t.startedAt),
+		Players:   make([]*PlayerSummary, 0, len(t.order)),
+	}
+	for _, id := range t.order {
+		if player := t.players[id]; player.Name != "" {
+			summary.Players = append(summary.Players, player)
+		}
+	}
+	return summary
+}
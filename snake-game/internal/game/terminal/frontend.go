@@ -0,0 +1,96 @@
+package terminal
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"snake-game/internal/game/config"
+	"snake-game/internal/game/core"
+	"snake-game/internal/game/keymap"
+	proto "snake-game/internal/proto/gen"
+	"time"
+)
+
+const frameInterval = 50 * time.Millisecond
+
+// keyName matches every name parseKeys can produce.
+var keyName = regexp.MustCompile(`^(Arrow(Up|Down|Left|Right)|[A-Z]|Digit[0-9]|Space|Enter)$`)
+
+// Frontend plays in the terminal the lobby runs in. The lobby keeps the
+// main goroutine and hands the terminal over to each game in turn.
+type Frontend struct {
+	keys *keymap.Keymap
+}
+
+func NewFrontend() *Frontend {
+	return &Frontend{}
+}
+
+func (f *Frontend) Run(lobby func()) {
+	lobby()
+}
+
+func (f *Frontend) Play(g *core.Game, s *core.Session) {
+	t, err := Open()
+	if err != nil {
+		log.Printf("Terminal front-end unavailable: %v", err)
+		return
+	}
+	defer t.Close()
+	ticker := time.NewTicker(frameInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.Quit():
+			return
+		case <-s.Done():
+			return
+		case key := <-t.Keys():
+			g.Lock()
+			f.press(g, s, key)
+			g.Unlock()
+		case <-ticker.C:
+			g.Lock()
+			err := g.Step(s)
+			if err != nil {
+				s.SetNotice(err.Error())
+			} else {
+				field := s.Logic().GetField()
+				t.Write(Render(s.Logic().GetState(), field.Width, field.Height, g.OwnPlayerID(s)))
+			}
+			g.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (f *Frontend) press(g *core.Game, s *core.Session, key string) {
+	if g.Role() == proto.NodeRole_VIEWER {
+		if key == "Enter" {
+			g.RequestPlay(s)
+		}
+		return
+	}
+	if newDirection, ok := f.keys.Press(key, g.HeadDirection(s)); ok {
+		if err := g.Steer(s, newDirection); err != nil {
+			s.SetNotice(err.Error())
+		}
+	}
+}
+
+// SetControls takes any names; ones the terminal cannot type never match.
+func (f *Frontend) SetControls(c config.Controls) error {
+	f.keys = keymap.New(c)
+	return nil
+}
+
+func (f *Frontend) CheckKeys(names []string) error {
+	for _, name := range names {
+		if !keyName.MatchString(name) {
+			return fmt.Errorf("the terminal cannot read key %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// openInput reads keys from a non-blocking duplicate of stdin so Close can
+// interrupt the reader with a deadline and hand stdin back to the menus.
+func openInput() (*os.File, error) {
+	fd, err := syscall.Dup(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("duplicating stdin: %v", err)
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("configuring stdin: %v", err)
+	}
+	return os.NewFile(uintptr(fd), "stdin"), nil
+}
+
+func closeInput(in *os.File) {
+	in.Close()
+	syscall.SetNonblock(int(os.Stdin.Fd()), false)
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package terminal
+
+import "os"
+
+func openInput() (*os.File, error) {
+	return os.Stdin, nil
+}
+
+func closeInput(in *os.File) {}
@@ -0,0 +1,116 @@
+package terminal
+
+import (
+	"fmt"
+	"snake-game/internal/game/logic"
+	proto "snake-game/internal/proto/gen"
+	"sort"
+	"strings"
+)
+
+const (
+	reset      = "\x1b[0m"
+	colorGrid  = "\x1b[38;5;238m"
+	colorFood  = "\x1b[38;5;196m"
+	colorWall  = "\x1b[38;5;102m"
+	colorHead  = "\x1b[38;5;34m"
+	colorBody  = "\x1b[38;5;205m"
+	colorOwn   = "\x1b[38;5;226m"
+	colorDead  = "\x1b[38;5;95m"
+	colorFrame = "\x1b[38;5;60m"
+)
+
+type cell struct {
+	glyph string
+	color string
+}
+
+var (
+	emptyCell = cell{"· ", colorGrid}
+	foodCell  = cell{"◆ ", colorFood}
+)
+
+func Render(state *proto.GameState, width, height int32, ownID int32) string {
+	grid := make([][]cell, height)
+	for y := range grid {
+		grid[y] = make([]cell, width)
+		for x := range grid[y] {
+			grid[y][x] = emptyCell
+		}
+	}
+	put := func(coord *proto.GameState_Coord, c cell) {
+		if coord.GetX() >= 0 && coord.GetX() < width && coord.GetY() >= 0 && coord.GetY() < height {
+			grid[coord.GetY()][coord.GetX()] = c
+		}
+	}
+	for _, food := range state.GetFoods() {
+		put(food, foodCell)
+	}
+	for _, snake := range state.GetSnakes() {
+		points := snake.GetPoints()
+		for i := len(points) - 1; i >= 0; i-- {
+			put(points[i], snakeCell(snake, i == 0, ownID))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\x1b[H")
+	border := colorFrame + "┼" + strings.Repeat("┄┄", int(width)) + "┼" + reset + "\x1b[K\r\n"
+	sb.WriteString(border)
+	for _, row := range grid {
+		sb.WriteString(colorFrame + "┆" + reset)
+		color := ""
+		for _, c := range row {
+			if c.color != color {
+				sb.WriteString(c.color)
+				color = c.color
+			}
+			sb.WriteString(c.glyph)
+		}
+		sb.WriteString(colorFrame + "┆" + reset + "\x1b[K\r\n")
+	}
+	sb.WriteString(border)
+	writeScores(&sb, state, ownID)
+	sb.WriteString("arrows/bound keys steer, Esc or Ctrl+C leaves\x1b[K\r\n\x1b[J")
+	return sb.String()
+}
+
+func snakeCell(snake *proto.GameState_Snake, head bool, ownID int32) cell {
+	switch {
+	case logic.IsWallSnake(snake):
+		return cell{"██", colorWall}
+	case snake.GetState() == proto.GameState_Snake_ZOMBIE:
+		return cell{"▒▒", colorDead}
+	case head && snake.GetPlayerId() == ownID:
+		return cell{"██", colorOwn}
+	case head:
+		return cell{"██", colorHead}
+	default:
+		return cell{"▓▓", colorBody}
+	}
+}
+
+func writeScores(sb *strings.Builder, state *proto.GameState, ownID int32) {
+	players := append([]*proto.GamePlayer(nil), state.GetPlayers().GetPlayers()...)
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].GetScore() > players[j].GetScore()
+	})
+	alive := make(map[int32]bool)
+	for _, snake := range state.GetSnakes() {
+		if snake.GetState() == proto.GameState_Snake_ALIVE {
+			alive[snake.GetPlayerId()] = true
+		}
+	}
+	fmt.Fprintf(sb, "state #%d\x1b[K\r\n", state.GetStateOrder())
+	for _, player := range players {
+		marker := " "
+		if player.GetId() == ownID {
+			marker = ">"
+		}
+		status := "ALIVE"
+		if !alive[player.GetId()] {
+			status = "-"
+		}
+		fmt.Fprintf(sb, "%s %-20s %5d  %-6s %s\x1b[K\r\n", marker, player.GetName(), player.GetScore(), status, player.GetRole())
+	}
+}
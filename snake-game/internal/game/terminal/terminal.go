@@ -0,0 +1,191 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/term"
+	"os"
+	"snake-game/internal/game/keymap"
+	"strings"
+	"time"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAltScreen = "\x1b[?25h\x1b[?1049l"
+)
+
+type Terminal struct {
+	in       *os.File
+	out      *os.File
+	oldState *term.State
+	keys     chan string
+	quit     chan struct{}
+	done     chan struct{}
+}
+
+func Open() (*Terminal, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, errors.New("stdin is not a terminal")
+	}
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("switching terminal to raw mode: %v", err)
+	}
+	in, err := openInput()
+	if err != nil {
+		term.Restore(int(os.Stdin.Fd()), oldState)
+		return nil, err
+	}
+	t := &Terminal{
+		in:       in,
+		out:      os.Stdout,
+		oldState: oldState,
+		keys:     make(chan string, 16),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	t.out.WriteString(enterAltScreen)
+	go t.readKeys()
+	return t, nil
+}
+
+func (t *Terminal) Keys() <-chan string {
+	return t.keys
+}
+
+func (t *Terminal) Quit() <-chan struct{} {
+	return t.quit
+}
+
+func (t *Terminal) Write(frame string) {
+	t.out.WriteString(frame)
+}
+
+func (t *Terminal) Close() {
+	t.in.SetReadDeadline(time.Now())
+	select {
+	case <-t.done:
+	case <-time.After(100 * time.Millisecond):
+	}
+	closeInput(t.in)
+	t.out.WriteString(leaveAltScreen)
+	term.Restore(int(os.Stdin.Fd()), t.oldState)
+}
+
+// escapeTimeout is how long a lone ESC waits for the rest of an arrow key
+// sequence before it counts as the quit key. Terminals send the sequence in
+// one write, but it can still arrive split across reads.
+const escapeTimeout = 50 * time.Millisecond
+
+func (t *Terminal) readKeys() {
+	defer close(t.done)
+	chunks := make(chan []byte)
+	go func() {
+		defer close(chunks)
+		for {
+			buf := make([]byte, 64)
+			n, err := t.in.Read(buf)
+			if err != nil {
+				return
+			}
+			chunks <- buf[:n]
+		}
+	}()
+	var pending []byte
+	var timeout <-chan time.Time
+	for {
+		final := false
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return
+			}
+			pending = append(pending, chunk...)
+		case <-timeout:
+			final = true
+		}
+		var names []string
+		names, pending = parseKeys(pending, final)
+		timeout = nil
+		if len(pending) > 0 {
+			timeout = time.After(escapeTimeout)
+		}
+		t.send(names)
+	}
+}
+
+func (t *Terminal) send(names []string) {
+	for _, name := range names {
+		if name == "quit" {
+			select {
+			case <-t.quit:
+			default:
+				close(t.quit)
+			}
+			continue
+		}
+		select {
+		case t.keys <- name:
+		default:
+		}
+	}
+}
+
+var escapeSequences = map[string]string{
+	"\x1b[A": "ArrowUp",
+	"\x1b[B": "ArrowDown",
+	"\x1b[C": "ArrowRight",
+	"\x1b[D": "ArrowLeft",
+	"\x1bOA": "ArrowUp",
+	"\x1bOB": "ArrowDown",
+	"\x1bOC": "ArrowRight",
+	"\x1bOD": "ArrowLeft",
+}
+
+// parseKeys turns raw terminal input into ebiten key names, so the same
+// bindings from the settings file apply to both front-ends. Unless final is
+// set, an escape sequence cut off at the end of input is returned as rest
+// so the next read can complete it.
+func parseKeys(input []byte, final bool) (names []string, rest []byte) {
+	names = make([]string, 0, len(input))
+	for len(input) > 0 {
+		if !final && isPartialEscape(input) {
+			return names, input
+		}
+		matched := false
+		for seq, name := range escapeSequences {
+			if strings.HasPrefix(string(input), seq) {
+				names = append(names, name)
+				input = input[len(seq):]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		c := input[0]
+		input = input[1:]
+		switch {
+		case c == 0x03, c == 0x1b:
+			names = append(names, "quit")
+		case c == '\r', c == '\n':
+			names = append(names, "Enter")
+		default:
+			if name, ok := keymap.CharName(rune(c)); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+func isPartialEscape(input []byte) bool {
+	for seq := range escapeSequences {
+		if len(input) < len(seq) && strings.HasPrefix(seq, string(input)) {
+			return true
+		}
+	}
+	return false
+}
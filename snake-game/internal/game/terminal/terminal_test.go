@@ -0,0 +1,41 @@
+package terminal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		reads []string
+		final bool
+		want  []string
+		rest  string
+	}{
+		{"arrow in one read", []string{"\x1b[A"}, false, []string{"ArrowUp"}, ""},
+		{"arrow split after ESC", []string{"\x1b", "[A"}, false, []string{"ArrowUp"}, ""},
+		{"arrow split after bracket", []string{"\x1b[", "D"}, false, []string{"ArrowLeft"}, ""},
+		{"keys before a split arrow", []string{"w\x1bO", "B"}, false, []string{"W", "ArrowDown"}, ""},
+		{"lone ESC waits", []string{"\x1b"}, false, []string{}, "\x1b"},
+		{"lone ESC after timeout", []string{"\x1b"}, true, []string{"quit"}, ""},
+		{"ESC then letter", []string{"\x1bx"}, false, []string{"quit", "X"}, ""},
+		{"ctrl-c", []string{"\x03"}, false, []string{"quit"}, ""},
+		{"enter and space", []string{"\r "}, false, []string{"Enter", "Space"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			var pending []byte
+			for i, read := range tt.reads {
+				var names []string
+				final := tt.final && i == len(tt.reads)-1
+				names, pending = parseKeys(append(pending, read...), final)
+				got = append(got, names...)
+			}
+			if !slices.Equal(got, tt.want) || string(pending) != tt.rest {
+				t.Fatalf("got %q rest %q, want %q rest %q", got, pending, tt.want, tt.rest)
+			}
+		})
+	}
+}
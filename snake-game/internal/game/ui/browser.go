@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	proto "snake-game/internal/proto/gen"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SortKey int
+
+const (
+	SortByName SortKey = iota
+	SortByPlayers
+	SortByLatency
+)
+
+type GameListing struct {
+	Name     string
+	Master   string
+	Players  int
+	Width    int32
+	Height   int32
+	Food     int32
+	CanJoin  bool
+	Latency  time.Duration
+	LastSeen time.Time
+}
+
+// GameBrowser keys its listings by game name and master address, since two
+// masters may well pick the same name.
+type GameBrowser struct {
+	mu       sync.Mutex
+	listings map[string]*GameListing
+	live     bool
+	filter   string
+	sortKey  SortKey
+}
+
+func NewGameBrowser() *GameBrowser {
+	return &GameBrowser{
+		listings: make(map[string]*GameListing),
+	}
+}
+
+func listingKey(gameName string, master *net.UDPAddr) string {
+	return gameName + "@" + master.String()
+}
+
+func (b *GameBrowser) Upsert(game *proto.GameAnnouncement, master *net.UDPAddr, latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listings[listingKey(game.GetGameName(), master)] = &GameListing{
+		Name:     game.GetGameName(),
+		Master:   master.String(),
+		Players:  len(game.GetPlayers().GetPlayers()),
+		Width:    game.GetConfig().GetWidth(),
+		Height:   game.GetConfig().GetHeight(),
+		Food:     game.GetConfig().GetFoodStatic(),
+		CanJoin:  game.GetCanJoin(),
+		Latency:  latency,
+		LastSeen: time.Now(),
+	}
+	if b.live {
+		b.render()
+	}
+}
+
+func (b *GameBrowser) Remove(gameName string, master *net.UDPAddr) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.listings, listingKey(gameName, master))
+	if b.live {
+		b.render()
+	}
+}
+
+func (b *GameBrowser) StartLive(filter string, sortKey SortKey) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.live = true
+	b.filter = strings.ToLower(filter)
+	b.sortKey = sortKey
+	b.render()
+}
+
+func (b *GameBrowser) StopLive() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.live = false
+}
+
+func (b *GameBrowser) sorted() []*GameListing {
+	listings := make([]*GameListing, 0, len(b.listings))
+	for _, listing := range b.listings {
+		if b.filter != "" && !strings.Contains(strings.ToLower(listing.Name), b.filter) {
+			continue
+		}
+		listings = append(listings, listing)
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		a, c := listings[i], listings[j]
+		switch b.sortKey {
+		case SortByPlayers:
+			if a.Players != c.Players {
+				return a.Players > c.Players
+			}
+		case SortByLatency:
+			if a.Latency != c.Latency {
+				return a.Latency < c.Latency
+			}
+		}
+		if a.Name != c.Name {
+			return a.Name < c.Name
+		}
+		return a.Master < c.Master
+	})
+	return listings
+}
+
+func (b *GameBrowser) render() {
+	fmt.Print("\033[H\033[2J")
+	fmt.Println("=== AVAILABLE GAMES (live, press Enter to return) ===")
+	if b.filter != "" {
+		fmt.Printf("Filter: %q\n", b.filter)
+	}
+	listings := b.sorted()
+	if len(listings) == 0 {
+		fmt.Println("\nNo available games found.")
+		return
+	}
+	fmt.Println("Game Name            | Master                 | Players | Field   | Food | Ping   | Join")
+	fmt.Println("---------------------|------------------------|---------|---------|------|--------|-----")
+	for _, listing := range listings {
+		ping := "-"
+		if listing.Latency > 0 {
+			ping = fmt.Sprintf("%dms", listing.Latency.Milliseconds())
+		}
+		join := "yes"
+		if !listing.CanJoin {
+			join = "no"
+		}
+		fmt.Printf("%-20s | %-22s | %7d | %3dx%-3d | %4d | %6s | %s\n",
+			listing.Name, listing.Master, listing.Players, listing.Width, listing.Height, listing.Food, ping, join)
+	}
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"snake-game/internal/game/logic"
+	proto "snake-game/internal/proto/gen"
+	"strings"
+)
+
+const (
+	emptyCell = '.'
+	foodCell  = '*'
+	wallCell  = '#'
+)
+
+func RenderField(state *proto.GameState, width, height int32) string {
+	if width <= 0 || height <= 0 {
+		width, height = boundingSize(state)
+	}
+	grid := make([][]byte, height)
+	for y := range grid {
+		grid[y] = []byte(strings.Repeat(string(emptyCell), int(width)))
+	}
+	put := func(coord *proto.GameState_Coord, cell byte) {
+		if coord.GetX() >= 0 && coord.GetX() < width && coord.GetY() >= 0 && coord.GetY() < height {
+			grid[coord.GetY()][coord.GetX()] = cell
+		}
+	}
+	for _, food := range state.GetFoods() {
+		put(food, foodCell)
+	}
+	letter := 0
+	for _, snake := range state.GetSnakes() {
+		if logic.IsWallSnake(snake) {
+			for _, point := range snake.GetPoints() {
+				put(point, wallCell)
+			}
+			continue
+		}
+		head, body := byte('A'+letter%26), byte('a'+letter%26)
+		if snake.GetState() == proto.GameState_Snake_ZOMBIE {
+			head, body = 'Z', 'z'
+		}
+		letter++
+		points := snake.GetPoints()
+		for i := len(points) - 1; i >= 0; i-- {
+			if i == 0 {
+				put(points[i], head)
+			} else {
+				put(points[i], body)
+			}
+		}
+	}
+	var sb strings.Builder
+	for _, row := range grid {
+		sb.Write(row)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func boundingSize(state *proto.GameState) (int32, int32) {
+	var width, height int32
+	grow := func(coord *proto.GameState_Coord) {
+		width = max(width, coord.GetX()+1)
+		height = max(height, coord.GetY()+1)
+	}
+	for _, food := range state.GetFoods() {
+		grow(food)
+	}
+	for _, snake := range state.GetSnakes() {
+		for _, point := range snake.GetPoints() {
+			grow(point)
+		}
+	}
+	return width, height
+}
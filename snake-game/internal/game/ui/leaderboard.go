@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	"snake-game/internal/game/stats"
+	"sort"
+	"time"
+)
+
+type LeaderboardView int
+
+const (
+	AllTimeView LeaderboardView = iota + 1
+	PlayerView
+	ConfigView
+	BackView
+)
+
+const leaderboardSize = 10
+
+func (ui *ConsoleUI) ReadLeaderboardView() LeaderboardView {
+	fmt.Println("\n=== LEADERBOARD ===")
+	fmt.Println("1. All-time")
+	fmt.Println("2. By player name")
+	fmt.Println("3. By game config")
+	fmt.Println("4. Back")
+	fmt.Print("Choose option: ")
+	return LeaderboardView(ui.readIntInput(1, 4))
+}
+
+func (ui *ConsoleUI) ReadLeaderboardPlayer() string {
+	fmt.Print("Enter player name: ")
+	return ui.readStringInput()
+}
+
+func (ui *ConsoleUI) ShowLeaderboard(title string, entries []*stats.LeaderboardEntry) {
+	fmt.Printf("\n=== %s ===\n", title)
+	if len(entries) == 0 {
+		fmt.Println("No finished matches yet.")
+		return
+	}
+	fmt.Println(" #  | Player Name          | Games | Wins | Best | Total | Kills | Max len")
+	fmt.Println("----|----------------------|-------|------|------|-------|-------|--------")
+	for i, entry := range entries {
+		if i == leaderboardSize {
+			break
+		}
+		fmt.Printf("%3d | %-20s | %5d | %4d | %4d | %5d | %5d | %7d\n",
+			i+1, entry.Name, entry.Games, entry.Wins, entry.BestScore, entry.TotalScore, entry.Kills, entry.MaxLength)
+	}
+}
+
+func (ui *ConsoleUI) ShowPlayerHistory(name string, matches []*stats.PlayerMatch) {
+	fmt.Printf("\n=== MATCH HISTORY: %s ===\n", name)
+	if len(matches) == 0 {
+		fmt.Println("No matches found for this player.")
+		return
+	}
+	fmt.Println("Date             | Game                 | Rank | Score | Kills | Duration | Death")
+	fmt.Println("-----------------|----------------------|------|-------|-------|----------|------")
+	for _, pm := range matches {
+		death := pm.Player.Cause
+		if death == stats.CauseSurvived {
+			death = "survived"
+		}
+		if pm.Player.KilledBy != "" {
+			death = fmt.Sprintf("%s (%s)", death, pm.Player.KilledBy)
+		}
+		fmt.Printf("%-16s | %-20s | %2d/%-2d| %5d | %5d | %8s | %s\n",
+			pm.Match.EndedAt.Format("2006-01-02 15:04"), pm.Match.GameName, pm.Rank, len(pm.Match.Players),
+			pm.Player.Score, pm.Player.Kills, pm.Match.Duration.Round(time.Second), death)
+	}
+}
+
+func (ui *ConsoleUI) ShowConfigLeaderboards(boards map[string][]*stats.LeaderboardEntry) {
+	if len(boards) == 0 {
+		fmt.Println("\nNo finished matches yet.")
+		return
+	}
+	keys := make([]string, 0, len(boards))
+	for key := range boards {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ui.ShowLeaderboard(key, boards[key])
+	}
+}
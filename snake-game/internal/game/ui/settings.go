@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+	"snake-game/internal/game/config"
+	"strconv"
+	"strings"
+)
+
+type SettingsOption int
+
+const (
+	RebindUp SettingsOption = iota + 1
+	RebindDown
+	RebindLeft
+	RebindRight
+	RebindTurnLeft
+	RebindTurnRight
+	ToggleRelative
+	ToggleGamepad
+	SetDeadzone
+	ResetControls
+	SaveAndBack
+)
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
+
+func (ui *ConsoleUI) ReadSettingsOption(c config.Controls) SettingsOption {
+	fmt.Println("\n=== CONTROLS ===")
+	fmt.Printf("1.  Up:          %s\n", strings.Join(c.Up, ", "))
+	fmt.Printf("2.  Down:        %s\n", strings.Join(c.Down, ", "))
+	fmt.Printf("3.  Left:        %s\n", strings.Join(c.Left, ", "))
+	fmt.Printf("4.  Right:       %s\n", strings.Join(c.Right, ", "))
+	fmt.Printf("5.  Turn left:   %s\n", strings.Join(c.TurnLeft, ", "))
+	fmt.Printf("6.  Turn right:  %s\n", strings.Join(c.TurnRight, ", "))
+	fmt.Printf("7.  Relative steering: %s\n", onOff(c.Relative))
+	fmt.Printf("8.  Gamepad:     %s\n", onOff(c.Gamepad))
+	fmt.Printf("9.  Stick deadzone: %.2f\n", c.Deadzone)
+	fmt.Println("10. Reset to defaults")
+	fmt.Println("11. Save and back")
+	fmt.Print("Choose option: ")
+	return SettingsOption(ui.readIntInput(1, 11))
+}
+
+func (ui *ConsoleUI) ReadKeyNames(action string) []string {
+	fmt.Printf("Keys for %s, separated by spaces (e.g. W ArrowUp Numpad8): ", action)
+	names := strings.FieldsFunc(ui.readStringInput(), func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+	return names
+}
+
+func (ui *ConsoleUI) ReadDeadzone() float64 {
+	for {
+		fmt.Print("Deadzone between 0.05 and 0.95: ")
+		value, err := strconv.ParseFloat(ui.readStringInput(), 64)
+		if err == nil && value >= 0.05 && value <= 0.95 {
+			return value
+		}
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"os"
 	"snake-game/internal/game/logic"
+	"snake-game/internal/game/match"
 	proto "snake-game/internal/proto/gen"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ConsoleUI struct {
@@ -24,35 +26,48 @@ type MenuOption int
 
 const (
 	StartNewGame MenuOption = iota + 1
+	ResumeGame
 	JoinGame
+	ConnectAddress
 	ShowGames
+	Leaderboard
+	Settings
 	Exit
 )
 
 func (ui *ConsoleUI) ShowMainMenu() MenuOption {
 	fmt.Println("\n=== SNAKE MULTIPLAYER ===")
 	fmt.Println("1. Start new game")
-	fmt.Println("2. Join existing game")
-	fmt.Println("3. Show available games")
-	fmt.Println("4. Exit")
+	fmt.Println("2. Resume saved game")
+	fmt.Println("3. Join existing game")
+	fmt.Println("4. Connect to address")
+	fmt.Println("5. Show available games")
+	fmt.Println("6. Leaderboard")
+	fmt.Println("7. Controls")
+	fmt.Println("8. Exit")
 	fmt.Print("Choose option: ")
 
-	return MenuOption(ui.readIntInput(1, 4))
+	return MenuOption(ui.readIntInput(1, 8))
 }
 
-func (ui *ConsoleUI) ShowGameList(games []*proto.GameAnnouncement) {
-	if len(games) == 0 {
-		fmt.Println("\nNo available games found.")
-		return
-	}
-	fmt.Println("\n=== AVAILABLE GAMES ===")
-	for _, game := range games {
-		fmt.Printf("%s (Players: %d)\n", game.GameName, len(game.Players.Players))
-		fmt.Printf("   Field: %dx%d, Food: %d\n",
-			game.Config.Width, game.Config.Height, game.Config.FoodStatic)
+func (ui *ConsoleUI) ReadBrowseOptions() (string, SortKey) {
+	fmt.Print("Filter by name (empty for all): ")
+	filter := ui.readStringInput()
+	fmt.Print("Sort by (name, players, latency): ")
+	switch strings.ToLower(ui.readStringInput()) {
+	case "players":
+		return filter, SortByPlayers
+	case "latency", "ping":
+		return filter, SortByLatency
+	default:
+		return filter, SortByName
 	}
 }
 
+func (ui *ConsoleUI) WaitForEnter() {
+	ui.scanner.Scan()
+}
+
 func (ui *ConsoleUI) ReadJoinInfo() (string, proto.NodeRole) {
 	fmt.Print("Enter game name to join: ")
 	gameName := ui.readStringInput()
@@ -62,6 +77,11 @@ func (ui *ConsoleUI) ReadJoinInfo() (string, proto.NodeRole) {
 	return gameName, role
 }
 
+func (ui *ConsoleUI) ReadAddress() string {
+	fmt.Print("Enter master address (host:port or [ipv6]:port): ")
+	return ui.readStringInput()
+}
+
 func (ui *ConsoleUI) readPlayerRole() proto.NodeRole {
 	for {
 		fmt.Print("Enter mode (possible values are NORMAL and VIEWER): ")
@@ -78,6 +98,50 @@ func (ui *ConsoleUI) readPlayerRole() proto.NodeRole {
 	}
 }
 
+// ReadSavedGame lets the user pick one of the listed saves and returns its
+// index, or -1 to go back.
+func (ui *ConsoleUI) ReadSavedGame(saves []string) int {
+	fmt.Println("=== SAVED GAMES ===")
+	for i, save := range saves {
+		fmt.Printf("%d. %s\n", i+1, save)
+	}
+	fmt.Println("0. Back")
+	fmt.Print("Choose game: ")
+	return ui.readIntInput(0, len(saves)) - 1
+}
+
+// ReadMaster lets the user pick between masters announcing the same game
+// name and returns its index, or -1 to go back.
+func (ui *ConsoleUI) ReadMaster(games []string) int {
+	fmt.Println("Several masters host a game with this name:")
+	for i, game := range games {
+		fmt.Printf("%d. %s\n", i+1, game)
+	}
+	fmt.Println("0. Back")
+	fmt.Print("Choose master: ")
+	return ui.readIntInput(0, len(games)) - 1
+}
+
+// ReadMatchOptions asks the master whether to play a match. Zero rounds
+// means an endless game.
+func (ui *ConsoleUI) ReadMatchOptions() match.Options {
+	fmt.Print("Match rounds (0 for an endless game): ")
+	rounds := ui.readIntInput(0, 99)
+	if rounds == 0 {
+		return match.Options{}
+	}
+	fmt.Print("Round length in seconds (0 for no limit): ")
+	seconds := ui.readIntInput(0, 3600)
+	fmt.Print("Score target (0 for none): ")
+	target := ui.readIntInput(0, 10000)
+	return match.Options{
+		Rounds:      rounds,
+		Duration:    time.Duration(seconds) * time.Second,
+		ScoreTarget: int32(target),
+		Countdown:   match.DefaultCountdown,
+	}
+}
+
 func (ui *ConsoleUI) ReadGameName() string {
 	fmt.Print("Enter game name: ")
 	if ui.scanner.Scan() {
@@ -0,0 +1,234 @@
+package window
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"image/color"
+	"log"
+	"snake-game/internal/game/config"
+	"snake-game/internal/game/controls"
+	"snake-game/internal/game/core"
+	"snake-game/internal/game/graphics"
+	"snake-game/internal/game/ui"
+	proto "snake-game/internal/proto/gen"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Window plays in an ebiten window. ebiten must own the main thread and can
+// only run once per process, so the lobby runs in a goroutine; the window
+// opens with the first game and then stays up, showing whichever game the
+// lobby starts next. mu guards what Play hands over to the ebiten loop.
+type Window struct {
+	mu              sync.Mutex
+	controller      *controls.Controller
+	game            *core.Game
+	session         *core.Session
+	renderer        *graphics.Renderer
+	lastScoreUpdate time.Time
+	adminOpen       bool
+	adminSelected   int
+	start           chan struct{}
+	startOnce       sync.Once
+}
+
+func New() *Window {
+	return &Window{start: make(chan struct{})}
+}
+
+func (w *Window) Run(lobby func()) {
+	ebiten.SetWindowSize(600, 600)
+	ebiten.SetWindowTitle("Snake Game")
+	go lobby()
+	<-w.start
+	if err := ebiten.RunGame(w); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func (w *Window) Play(g *core.Game, s *core.Session) {
+	w.mu.Lock()
+	w.game = g
+	w.renderer = graphics.NewRenderer(s.Logic())
+	w.adminOpen = false
+	w.adminSelected = 0
+	w.session = s
+	w.mu.Unlock()
+	w.startOnce.Do(func() { close(w.start) })
+	<-s.Done()
+}
+
+func (w *Window) SetControls(c config.Controls) error {
+	controller, err := controls.NewController(c)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.controller = controller
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *Window) CheckKeys(names []string) error {
+	_, err := controls.ParseKeys(names)
+	return err
+}
+
+func (w *Window) Update() error {
+	if ebiten.IsWindowBeingClosed() {
+		return ebiten.Termination
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s := w.session
+	if s == nil || s.Ended() {
+		return nil
+	}
+	w.game.Lock()
+	defer w.game.Unlock()
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		s.End()
+		return nil
+	}
+	w.handleInput(s)
+	if err := w.game.Step(s); err != nil {
+		return err
+	}
+	now := time.Now()
+	if now.Sub(w.lastScoreUpdate) >= 250*time.Millisecond {
+		ui.ShowScores(s.Logic())
+		w.lastScoreUpdate = now
+	}
+	return nil
+}
+
+func (w *Window) handleInput(s *core.Session) {
+	if w.handleAdminInput(s) {
+		return
+	}
+	if w.game.Role() == proto.NodeRole_VIEWER {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			w.game.RequestPlay(s)
+		}
+		return
+	}
+	if newDirection, ok := w.controller.Poll(w.game.HeadDirection(s)); ok {
+		if err := w.game.Steer(s, newDirection); err != nil {
+			log.Printf("Error steering: %v", err)
+		}
+	}
+}
+
+// handleAdminInput returns true while the admin panel is open, so the
+// arrow keys select players instead of steering.
+func (w *Window) handleAdminInput(s *core.Session) bool {
+	if w.game.Role() != proto.NodeRole_MASTER {
+		w.adminOpen = false
+		return false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		w.adminOpen = !w.adminOpen
+	}
+	if !w.adminOpen {
+		return false
+	}
+	targets := w.game.AdminTargets(s)
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		w.adminSelected--
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		w.adminSelected++
+	case inpututil.IsKeyJustPressed(ebiten.KeyK):
+		if w.adminSelected < len(targets) {
+			w.game.Kick(s, targets[w.adminSelected].GetId())
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyP):
+		w.game.TogglePause(s)
+	case inpututil.IsKeyJustPressed(ebiten.KeyJ):
+		w.game.ToggleJoining()
+	case inpututil.IsKeyJustPressed(ebiten.KeyE):
+		w.game.EndGame(s)
+	case inpututil.IsKeyJustPressed(ebiten.KeyS):
+		w.game.Save(s)
+	}
+	w.adminSelected = max(0, min(w.adminSelected, len(targets)-1))
+	return true
+}
+
+func (w *Window) Draw(screen *ebiten.Image) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s := w.session
+	if s == nil {
+		ebitenutil.DebugPrint(screen, "No game running, pick one in the console menu")
+		return
+	}
+	w.game.Lock()
+	defer w.game.Unlock()
+	w.updateCellSize(s)
+	w.renderer.Draw(screen)
+	if s.Results() != "" {
+		drawResults(screen, s)
+		return
+	}
+	w.drawOverlay(screen, s)
+}
+
+func (w *Window) updateCellSize(s *core.Session) {
+	field := s.Logic().GetField()
+	if field != nil {
+		width, height := ebiten.WindowSize()
+		cellWidth := width / int(field.Width)
+		cellHeight := height / int(field.Height)
+		cellSize := min(cellWidth, cellHeight)
+		w.renderer.SetCellSize(cellSize)
+	}
+}
+
+func (w *Window) Layout(width, height int) (int, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if s := w.session; s != nil {
+		w.updateCellSize(s)
+	}
+	return width, height
+}
+
+// drawResults dims the field and shows the final standings of a match.
+func drawResults(screen *ebiten.Image, s *core.Session) {
+	bounds := screen.Bounds()
+	shade := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	shade.Fill(color.RGBA{A: 192})
+	screen.DrawImage(shade, nil)
+	ebitenutil.DebugPrintAt(screen, s.Results()+"\n\nEsc to leave", 16, 16)
+}
+
+func (w *Window) drawOverlay(screen *ebiten.Image, s *core.Session) {
+	var sb strings.Builder
+	if s.Paused() {
+		sb.WriteString("PAUSED\n")
+	}
+	if w.game.Role() == proto.NodeRole_VIEWER {
+		sb.WriteString("Watching - press Enter to play\n")
+	}
+	if s.Notice() != "" {
+		sb.WriteString(s.Notice() + "\n")
+	}
+	if w.adminOpen {
+		sb.WriteString("=== ADMIN (Tab closes) ===\n")
+		sb.WriteString(fmt.Sprintf("P pause/resume  J joining: %v  S save  E end game  K kick\n", w.game.CanJoin()))
+		for i, player := range w.game.AdminTargets(s) {
+			marker := "  "
+			if i == w.adminSelected {
+				marker = "> "
+			}
+			sb.WriteString(fmt.Sprintf("%s%-16s %-6s %d\n", marker, player.GetName(), player.GetRole(), player.GetScore()))
+		}
+	}
+	if sb.Len() > 0 {
+		ebitenutil.DebugPrint(screen, sb.String())
+	}
+}
@@ -0,0 +1,190 @@
+package loadtest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"snake-game/internal/conformance"
+	"snake-game/internal/game/bots"
+	"snake-game/internal/game/logic"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"time"
+)
+
+const (
+	joinTimeout  = 3 * time.Second
+	checkEvery   = 100 * time.Millisecond
+	silenceLimit = 5
+)
+
+// outcome is how one client's session ended.
+type outcome int
+
+const (
+	finished outcome = iota
+	left
+	timedOut
+	joinFailed
+	gameEnded
+)
+
+// client is one simulated player: a bare UDP peer that joins, steers with a
+// bot strategy and at random leaves or falls silent.
+type client struct {
+	name      string
+	target    *net.UDPAddr
+	game      *prt.GameAnnouncement
+	strategy  bots.Strategy
+	rnd       *rand.Rand
+	opts      Options
+	stats     *collector
+	peer      *conformance.Peer
+	playerID  int32
+	seen      map[int64]struct{}
+	lastOrder int32
+	lastState time.Time
+}
+
+func (c *client) run(deadline time.Time) (outcome, error) {
+	peer, err := conformance.NewPeer()
+	if err != nil {
+		return joinFailed, err
+	}
+	defer peer.Close()
+	c.peer = peer
+	c.seen = make(map[int64]struct{})
+	c.lastOrder = -1
+	if err := c.join(); err != nil {
+		c.stats.joinFailed()
+		return joinFailed, err
+	}
+	return c.play(deadline), nil
+}
+
+func (c *client) join() error {
+	sentAt := time.Now()
+	seq, err := c.peer.Send(&prt.GameMessage{Type: &prt.GameMessage_Join{Join: &prt.GameMessage_JoinMsg{
+		PlayerType:    prt.PlayerType_ROBOT,
+		PlayerName:    c.name,
+		GameName:      c.game.GetGameName(),
+		RequestedRole: prt.NodeRole_NORMAL,
+	}}}, c.target)
+	if err != nil {
+		return fmt.Errorf("sending join: %v", err)
+	}
+	received, err := c.peer.Expect(joinTimeout, func(r *conformance.Received) bool {
+		return r.Msg.GetError() != nil || r.Msg.GetAck() != nil && r.Msg.GetMsgSeq() == seq && r.Msg.GetReceiverId() != 0
+	})
+	if err != nil {
+		return fmt.Errorf("join: %v", err)
+	}
+	if received.Msg.GetError() != nil {
+		return fmt.Errorf("join refused: %s", received.Msg.GetError().GetErrorMessage())
+	}
+	c.playerID = received.Msg.GetReceiverId()
+	c.peer.SetPlayerID(c.playerID)
+	c.stats.joined(received.At.Sub(sentAt))
+	return nil
+}
+
+func (c *client) play(deadline time.Time) outcome {
+	check := time.NewTicker(checkEvery)
+	defer check.Stop()
+	delay := time.Duration(c.game.GetConfig().GetStateDelayMs()) * time.Millisecond
+	leaveChance := c.opts.LeaveRate * checkEvery.Seconds()
+	timeoutChance := c.opts.TimeoutRate * checkEvery.Seconds()
+	var silentSince time.Time
+	for {
+		select {
+		case received := <-c.peer.Inbox():
+			switch {
+			case received.Msg.GetState() != nil:
+				if c.onState(received, silentSince) {
+					c.stats.timedOut(received.At.Sub(silentSince))
+					return timedOut
+				}
+			case received.Msg.GetError().GetErrorMessage() == network.GameEndedMessage:
+				return gameEnded
+			}
+		case now := <-check.C:
+			switch {
+			case !silentSince.IsZero():
+				if now.Sub(silentSince) > silenceLimit*delay {
+					c.stats.timedOut(0)
+					return timedOut
+				}
+			case now.After(deadline):
+				c.leave()
+				return finished
+			case c.rnd.Float64() < leaveChance:
+				c.leave()
+				c.stats.left()
+				return left
+			case c.rnd.Float64() < timeoutChance:
+				c.peer.SetAutoAck(false)
+				silentSince = now
+			}
+		}
+	}
+}
+
+// onState records the delivery of a state and steers in reply. While the
+// client plays dead it only watches for the master demoting it, and reports
+// when that happens.
+func (c *client) onState(received *conformance.Received, silentSince time.Time) bool {
+	if _, dup := c.seen[received.Msg.GetMsgSeq()]; dup {
+		c.stats.retransmitted()
+		return false
+	}
+	c.seen[received.Msg.GetMsgSeq()] = struct{}{}
+	state := received.Msg.GetState().GetState()
+	if state.GetStateOrder() <= c.lastOrder {
+		return false
+	}
+	var gap time.Duration
+	if !c.lastState.IsZero() {
+		gap = received.At.Sub(c.lastState)
+	}
+	c.stats.delivered(gap, state.GetStateOrder()-c.lastOrder)
+	c.lastOrder = state.GetStateOrder()
+	c.lastState = received.At
+	if !silentSince.IsZero() {
+		for _, player := range state.GetPlayers().GetPlayers() {
+			if player.GetId() == c.playerID {
+				return player.GetRole() == prt.NodeRole_VIEWER
+			}
+		}
+		return false
+	}
+	c.steer(state)
+	return false
+}
+
+func (c *client) steer(state *prt.GameState) {
+	var snake *prt.GameState_Snake
+	for _, s := range state.GetSnakes() {
+		if s.GetPlayerId() == c.playerID {
+			snake = s
+		}
+	}
+	if snake == nil || snake.GetState() != prt.GameState_Snake_ALIVE {
+		return
+	}
+	field := logic.NewField(c.game.GetConfig().GetWidth(), c.game.GetConfig().GetHeight())
+	state = logic.DecodeState(state, field.Width, field.Height)
+	dir := c.strategy.Decide(bots.View{State: state, Field: field, PlayerID: c.playerID, Rand: c.rnd})
+	if dir == snake.GetHeadDirection() {
+		return
+	}
+	if _, err := c.peer.Send(&prt.GameMessage{Type: &prt.GameMessage_Steer{Steer: &prt.GameMessage_SteerMsg{Direction: dir}}}, c.target); err == nil {
+		c.stats.steered()
+	}
+}
+
+func (c *client) leave() {
+	c.peer.Send(&prt.GameMessage{Type: &prt.GameMessage_RoleChange{RoleChange: &prt.GameMessage_RoleChangeMsg{
+		SenderRole:   prt.NodeRole_VIEWER,
+		ReceiverRole: prt.NodeRole_MASTER,
+	}}}, c.target)
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package loadtest
+
+import "time"
+
+func cpuTime() (time.Duration, bool) {
+	return 0, false
+}
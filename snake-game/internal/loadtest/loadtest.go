@@ -0,0 +1,153 @@
+package loadtest
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"snake-game/internal/conformance"
+	"snake-game/internal/game/bots"
+	"snake-game/internal/game/headless"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"sync"
+	"time"
+)
+
+const (
+	rejoinPause = 500 * time.Millisecond
+	cpuSample   = 1 * time.Second
+)
+
+// Options configure a load test. Target is the master under test; without
+// one an in-process master is hosted with Config, which also lets the test
+// measure its tick times and CPU use.
+type Options struct {
+	Clients     int
+	Duration    time.Duration
+	Target      *net.UDPAddr
+	Config      *prt.GameConfig
+	Strategy    string
+	LeaveRate   float64
+	TimeoutRate float64
+	Seed        uint64
+}
+
+// Run keeps Clients simulated players in the game for Duration. A client
+// that leaves, times out or fails to join is replaced by a fresh one, so
+// the master also sees a steady stream of joins.
+func Run(opts Options) (*Report, error) {
+	strategy, err := bots.Get(opts.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	stats := newCollector()
+	target := opts.Target
+	if target == nil {
+		stop, addr, err := hostMaster(opts.Config, stats)
+		if err != nil {
+			return nil, err
+		}
+		defer stop()
+		target = addr
+	}
+	game, err := discover(target)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	deadline := started.Add(opts.Duration)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Clients; i++ {
+		wg.Add(1)
+		go func(slot int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewPCG(opts.Seed, uint64(slot)))
+			for n := 0; time.Now().Before(deadline); n++ {
+				c := &client{
+					name:     fmt.Sprintf("load-%d-%d", slot, n),
+					target:   target,
+					game:     game,
+					strategy: strategy,
+					rnd:      rnd,
+					opts:     opts,
+					stats:    stats,
+				}
+				result, err := c.run(deadline)
+				if err != nil {
+					log.Printf("Client %s: %v", c.name, err)
+				}
+				if result == gameEnded {
+					return
+				}
+				time.Sleep(rejoinPause)
+			}
+		}(i)
+	}
+	wg.Wait()
+	delay := time.Duration(game.GetConfig().GetStateDelayMs()) * time.Millisecond
+	return stats.report(opts, target, delay, time.Since(started)), nil
+}
+
+func discover(target *net.UDPAddr) (*prt.GameAnnouncement, error) {
+	peer, err := conformance.NewPeer()
+	if err != nil {
+		return nil, err
+	}
+	defer peer.Close()
+	if _, err := peer.Send(&prt.GameMessage{Type: &prt.GameMessage_Discover{Discover: &prt.GameMessage_DiscoverMsg{}}}, target); err != nil {
+		return nil, fmt.Errorf("sending discover: %v", err)
+	}
+	received, err := peer.Expect(joinTimeout, func(r *conformance.Received) bool {
+		return len(r.Msg.GetAnnouncement().GetGames()) > 0
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no game at %s: %v", target, err)
+	}
+	return received.Msg.GetAnnouncement().GetGames()[0], nil
+}
+
+// hostMaster runs a headless master in this process and samples its tick
+// times and the process's CPU use while the test runs.
+func hostMaster(cfg *prt.GameConfig, stats *collector) (func(), *net.UDPAddr, error) {
+	mgr := network.NewNetworkManager(prt.NodeRole_NORMAL, nil)
+	mgr.SetMulticastInterface("")
+	if err := mgr.Start(); err != nil {
+		return nil, nil, fmt.Errorf("starting in-process master: %v", err)
+	}
+	host := headless.NewHost("loadtest", "master", cfg, mgr)
+	host.SetTickObserver(stats.ticked)
+	host.Start()
+	done := make(chan struct{})
+	go sampleCPU(stats, done)
+	stop := func() {
+		close(done)
+		host.Stop()
+		mgr.Close()
+	}
+	return stop, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: mgr.LocalAddr().Port}, nil
+}
+
+func sampleCPU(stats *collector, done chan struct{}) {
+	ticker := time.NewTicker(cpuSample)
+	defer ticker.Stop()
+	last, ok := cpuTime()
+	if !ok {
+		return
+	}
+	lastAt := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			used, ok := cpuTime()
+			if !ok {
+				return
+			}
+			stats.cpu(float64(used-last) / float64(now.Sub(lastAt)))
+			last, lastAt = used, now
+		case <-done:
+			return
+		}
+	}
+}
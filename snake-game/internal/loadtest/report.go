@@ -0,0 +1,222 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// collector gathers measurements from every client and the master.
+type collector struct {
+	mu            sync.Mutex
+	joinLatency   []time.Duration
+	gaps          []time.Duration
+	ticks         []time.Duration
+	timeoutDetect []time.Duration
+	cpuLoad       []float64
+	joins         int
+	joinFailures  int
+	leaves        int
+	timeouts      int
+	undetected    int
+	states        int
+	lost          int
+	retransmits   int
+	steers        int
+}
+
+func newCollector() *collector {
+	return &collector{}
+}
+
+func (c *collector) joined(latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.joins++
+	c.joinLatency = append(c.joinLatency, latency)
+}
+
+func (c *collector) joinFailed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.joinFailures++
+}
+
+func (c *collector) left() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.leaves++
+}
+
+// timedOut counts a client that went silent; detect is how long the master
+// took to demote it, or zero if it never did.
+func (c *collector) timedOut(detect time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeouts++
+	if detect == 0 {
+		c.undetected++
+		return
+	}
+	c.timeoutDetect = append(c.timeoutDetect, detect)
+}
+
+// delivered counts a new state; gap is the time since the previous one and
+// advance how far state_order moved, so skipped states count as lost.
+func (c *collector) delivered(gap time.Duration, advance int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.states++
+	if gap > 0 {
+		c.gaps = append(c.gaps, gap)
+		c.lost += int(advance) - 1
+	}
+}
+
+func (c *collector) retransmitted() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retransmits++
+}
+
+func (c *collector) steered() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.steers++
+}
+
+func (c *collector) ticked(elapsed time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ticks = append(c.ticks, elapsed)
+}
+
+func (c *collector) cpu(load float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cpuLoad = append(c.cpuLoad, load)
+}
+
+// Percentiles summarise a set of measurements.
+type Percentiles struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+func percentiles(values []float64) Percentiles {
+	if len(values) == 0 {
+		return Percentiles{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	at := func(q float64) float64 {
+		return sorted[int(q*float64(len(sorted)-1))]
+	}
+	return Percentiles{Count: len(sorted), P50: at(0.5), P90: at(0.9), P99: at(0.99), Max: sorted[len(sorted)-1]}
+}
+
+func millis(durations []time.Duration) []float64 {
+	values := make([]float64, len(durations))
+	for i, d := range durations {
+		values[i] = float64(d) / float64(time.Millisecond)
+	}
+	return values
+}
+
+type Report struct {
+	Target         string        `json:"target"`
+	Clients        int           `json:"clients"`
+	Elapsed        time.Duration `json:"elapsed_ns"`
+	Joins          int           `json:"joins"`
+	JoinFailures   int           `json:"join_failures"`
+	Leaves         int           `json:"leaves"`
+	Timeouts       int           `json:"timeouts"`
+	Undetected     int           `json:"undetected_timeouts"`
+	States         int           `json:"states"`
+	LostStates     int           `json:"lost_states"`
+	Retransmits    int           `json:"retransmits"`
+	RetransmitRate float64       `json:"retransmit_rate"`
+	Steers         int           `json:"steers"`
+	JoinLatencyMs  Percentiles   `json:"join_latency_ms"`
+	StateGapMs     Percentiles   `json:"state_gap_ms"`
+	JitterMs       Percentiles   `json:"jitter_ms"`
+	TimeoutMs      Percentiles   `json:"timeout_detection_ms"`
+	TickMs         *Percentiles  `json:"tick_ms,omitempty"`
+	CPU            *Percentiles  `json:"cpu_cores,omitempty"`
+}
+
+// report sums up the run. Jitter is how far each gap between states strays
+// from the game's state delay.
+func (c *collector) report(opts Options, target *net.UDPAddr, delay, elapsed time.Duration) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	jitter := make([]time.Duration, len(c.gaps))
+	for i, gap := range c.gaps {
+		jitter[i] = (gap - delay).Abs()
+	}
+	r := &Report{
+		Target:        target.String(),
+		Clients:       opts.Clients,
+		Elapsed:       elapsed,
+		Joins:         c.joins,
+		JoinFailures:  c.joinFailures,
+		Leaves:        c.leaves,
+		Timeouts:      c.timeouts,
+		Undetected:    c.undetected,
+		States:        c.states,
+		LostStates:    c.lost,
+		Retransmits:   c.retransmits,
+		Steers:        c.steers,
+		JoinLatencyMs: percentiles(millis(c.joinLatency)),
+		StateGapMs:    percentiles(millis(c.gaps)),
+		JitterMs:      percentiles(millis(jitter)),
+		TimeoutMs:     percentiles(millis(c.timeoutDetect)),
+	}
+	if c.states > 0 {
+		r.RetransmitRate = float64(c.retransmits) / float64(c.states+c.retransmits)
+	}
+	if opts.Target == nil {
+		ticks := percentiles(millis(c.ticks))
+		r.TickMs = &ticks
+		if len(c.cpuLoad) > 0 {
+			cpu := percentiles(c.cpuLoad)
+			r.CPU = &cpu
+		}
+	}
+	return r
+}
+
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "%d clients against %s for %s\n", r.Clients, r.Target, r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "joins %d, failed %d, leaves %d, timeouts %d (%d never detected)\n",
+		r.Joins, r.JoinFailures, r.Leaves, r.Timeouts, r.Undetected)
+	fmt.Fprintf(w, "states %d, lost %d, retransmitted %d (%.2f%%), steers sent %d\n",
+		r.States, r.LostStates, r.Retransmits, 100*r.RetransmitRate, r.Steers)
+	fmt.Fprintf(w, "%-22s %7s %9s %9s %9s %9s\n", "", "count", "p50", "p90", "p99", "max")
+	row := func(name string, p Percentiles) {
+		fmt.Fprintf(w, "%-22s %7d %9.2f %9.2f %9.2f %9.2f\n", name, p.Count, p.P50, p.P90, p.P99, p.Max)
+	}
+	row("join latency ms", r.JoinLatencyMs)
+	row("state gap ms", r.StateGapMs)
+	row("state jitter ms", r.JitterMs)
+	row("timeout detection ms", r.TimeoutMs)
+	if r.TickMs != nil {
+		row("master tick ms", *r.TickMs)
+	}
+	if r.CPU != nil {
+		row("process CPU cores", *r.CPU)
+	}
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -14,6 +14,8 @@ type ActivityManager struct {
 	pingTicker    *time.Ticker
 	timeoutTicker *time.Ticker
 	manager       *Manager
+	done          chan struct{}
+	closeOnce     sync.Once
 }
 
 func NewActivityManager(stateDelayMs int32, manager *Manager) *ActivityManager {
@@ -22,6 +24,7 @@ func NewActivityManager(stateDelayMs int32, manager *Manager) *ActivityManager {
 		lastRecv:     make(map[string]time.Time),
 		stateDelayMs: stateDelayMs,
 		manager:      manager,
+		done:         make(chan struct{}),
 	}
 	am.startMonitoring()
 	return am
@@ -41,9 +44,17 @@ func (am *ActivityManager) monitorActivity() {
 	for {
 		select {
 		case <-am.pingTicker.C:
+			// Resends go first: they count as traffic, so a node whose ping
+			// is still unacked gets it again under its msg_seq rather than
+			// a new ping that would replace it.
+			am.manager.mu.Lock()
+			am.manager.retransmit(time.Duration(am.stateDelayMs) * time.Millisecond / 10)
 			am.checkAndSendPings()
+			am.manager.mu.Unlock()
 		case <-am.timeoutTicker.C:
 			am.checkTimeouts()
+		case <-am.done:
+			return
 		case <-am.manager.closeChan:
 			return
 		}
@@ -68,7 +79,7 @@ func (am *ActivityManager) checkAndSendPings() {
 		if err == nil {
 
 			am.RecordMessageSent(addr)
-			go am.manager.sendPing(addr)
+			am.manager.sendPing(addr)
 		}
 	}
 }
@@ -91,7 +102,7 @@ func (am *ActivityManager) checkTimeouts() {
 		if err == nil {
 
 			am.RemoveNode(addr)
-			go am.manager.handleNodeTimeout(addr)
+			am.manager.handleNodeTimeout(addr)
 		}
 	}
 }
@@ -99,14 +110,14 @@ func (am *ActivityManager) checkTimeouts() {
 func (am *ActivityManager) RecordMessageSent(addr *net.UDPAddr) {
 	am.mu.Lock()
 	defer am.mu.Unlock()
-	addrStr := addr.String()
+	addrStr := normalizeAddr(addr)
 	am.lastSent[addrStr] = time.Now()
 }
 
 func (am *ActivityManager) RecordMessageReceived(addr *net.UDPAddr) {
 	am.mu.Lock()
 	defer am.mu.Unlock()
-	addrStr := addr.String()
+	addrStr := normalizeAddr(addr)
 	am.lastRecv[addrStr] = time.Now()
 	am.lastSent[addrStr] = time.Now()
 }
@@ -114,7 +125,7 @@ func (am *ActivityManager) RecordMessageReceived(addr *net.UDPAddr) {
 func (am *ActivityManager) AddNodeToMonitor(addr *net.UDPAddr) {
 	am.mu.Lock()
 	defer am.mu.Unlock()
-	addrStr := addr.String()
+	addrStr := normalizeAddr(addr)
 	now := time.Now()
 	am.lastSent[addrStr] = now
 	am.lastRecv[addrStr] = now
@@ -123,16 +134,19 @@ func (am *ActivityManager) AddNodeToMonitor(addr *net.UDPAddr) {
 func (am *ActivityManager) RemoveNode(addr *net.UDPAddr) {
 	am.mu.Lock()
 	defer am.mu.Unlock()
-	addrStr := addr.String()
+	addrStr := normalizeAddr(addr)
 	delete(am.lastRecv, addrStr)
 	delete(am.lastSent, addrStr)
 }
 
 func (am *ActivityManager) Close() {
-	if am.pingTicker != nil {
-		am.pingTicker.Stop()
-	}
-	if am.timeoutTicker != nil {
-		am.timeoutTicker.Stop()
-	}
+	am.closeOnce.Do(func() {
+		if am.pingTicker != nil {
+			am.pingTicker.Stop()
+		}
+		if am.timeoutTicker != nil {
+			am.timeoutTicker.Stop()
+		}
+		close(am.done)
+	})
 }
@@ -0,0 +1,64 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	prt "snake-game/internal/proto/gen"
+	"strconv"
+)
+
+// normalizeAddr identifies a peer regardless of how its address was written:
+// IPv4-mapped IPv6 addresses are unmapped and the zone is dropped, since the
+// addresses in GamePlayer never carry one.
+func normalizeAddr(addr *net.UDPAddr) string {
+	ap := addr.AddrPort()
+	return netip.AddrPortFrom(ap.Addr().Unmap().WithZone(""), ap.Port()).String()
+}
+
+// hostString is addr as it goes into GamePlayer.ip_address. The zone of a
+// link-local address names one of our own interfaces, which means nothing to
+// the other nodes, so it is left out.
+func hostString(addr *net.UDPAddr) string {
+	return addr.AddrPort().Addr().Unmap().WithZone("").String()
+}
+
+func sameAddr(a, b *net.UDPAddr) bool {
+	return normalizeAddr(a) == normalizeAddr(b)
+}
+
+func playerAddr(player *prt.GamePlayer) (*net.UDPAddr, error) {
+	if player.GetIpAddress() == "" {
+		return nil, fmt.Errorf("player %d has no address", player.GetId())
+	}
+	return net.ResolveUDPAddr("udp", net.JoinHostPort(player.GetIpAddress(), strconv.Itoa(int(player.GetPort()))))
+}
+
+func udpNetwork(ip net.IP) string {
+	if ip.To4() != nil {
+		return "udp4"
+	}
+	return "udp6"
+}
+
+// rememberZone notes the interface a link-local peer talks to us on, so
+// replies to its zone-less GamePlayer address can still be routed.
+func (m *Manager) rememberZone(addr *net.UDPAddr) {
+	if addr.Zone == "" || !addr.IP.IsLinkLocalUnicast() {
+		return
+	}
+	m.zones[addr.IP.String()] = addr.Zone
+}
+
+func (m *Manager) withZone(addr *net.UDPAddr) *net.UDPAddr {
+	if addr.Zone != "" || !addr.IP.IsLinkLocalUnicast() {
+		return addr
+	}
+	zone, ok := m.zones[addr.IP.String()]
+	if !ok {
+		return addr
+	}
+	zoned := *addr
+	zoned.Zone = zone
+	return &zoned
+}
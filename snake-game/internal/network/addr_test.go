@@ -0,0 +1,56 @@
+package network
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNormalizeAddr(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *net.UDPAddr
+		same bool
+	}{
+		{"ipv4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9192}, &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9192}, true},
+		{"ipv4-mapped", &net.UDPAddr{IP: net.ParseIP("::ffff:10.0.0.7"), Port: 9192}, &net.UDPAddr{IP: net.ParseIP("10.0.0.7"), Port: 9192}, true},
+		{"zone", &net.UDPAddr{IP: net.ParseIP("fe80::1"), Port: 9192, Zone: "eth0"}, &net.UDPAddr{IP: net.ParseIP("fe80::1"), Port: 9192}, true},
+		{"ipv6 spelling", &net.UDPAddr{IP: net.ParseIP("2001:db8:0:0::1"), Port: 1}, &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 1}, true},
+		{"port", &net.UDPAddr{IP: net.ParseIP("::1"), Port: 1}, &net.UDPAddr{IP: net.ParseIP("::1"), Port: 2}, false},
+		{"host", &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1}, &net.UDPAddr{IP: net.ParseIP("10.0.0.2"), Port: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameAddr(tt.a, tt.b); got != tt.same {
+				t.Errorf("sameAddr(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.same)
+			}
+		})
+	}
+}
+
+func TestHostString(t *testing.T) {
+	tests := []struct {
+		addr *net.UDPAddr
+		want string
+	}{
+		{&net.UDPAddr{IP: net.ParseIP("::ffff:192.168.1.5")}, "192.168.1.5"},
+		{&net.UDPAddr{IP: net.ParseIP("fe80::1"), Zone: "eth0"}, "fe80::1"},
+		{&net.UDPAddr{IP: net.ParseIP("2001:db8::1")}, "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		if got := hostString(tt.addr); got != tt.want {
+			t.Errorf("hostString(%s) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestWithZone(t *testing.T) {
+	m := NewNetworkManager(0, nil)
+	m.rememberZone(&net.UDPAddr{IP: net.ParseIP("fe80::1"), Port: 5000, Zone: "eth0"})
+	got := m.withZone(&net.UDPAddr{IP: net.ParseIP("fe80::1"), Port: 6000})
+	if got.Zone != "eth0" || got.Port != 6000 {
+		t.Errorf("withZone = %s, want [fe80::1%%eth0]:6000", got)
+	}
+	if got := m.withZone(&net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 1}); got.Zone != "" {
+		t.Errorf("global address got zone %q", got.Zone)
+	}
+}
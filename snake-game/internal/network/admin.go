@@ -0,0 +1,148 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"snake-game/internal/game/interfaces"
+	prt "snake-game/internal/proto/gen"
+	"strings"
+)
+
+const (
+	KickedMessage      = "Kicked by the master"
+	GameEndedMessage   = "Game ended by the master"
+	NotJoinableMessage = "Game is not accepting new players"
+)
+
+func IsKickMessage(message string) bool {
+	return strings.HasPrefix(message, KickedMessage)
+}
+
+func (m *Manager) SetErrorListener(listener interfaces.GameErrorListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errorListener = listener
+}
+
+func (m *Manager) SetCanJoin(canJoin bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.setCanJoin(canJoin)
+}
+
+func (m *Manager) setCanJoin(canJoin bool) {
+	if m.gameAnnounce != nil {
+		m.gameAnnounce.CanJoin = canJoin
+	}
+}
+
+func (m *Manager) CanJoin() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.gameAnnounce.GetCanJoin()
+}
+
+// KickPlayer tells a player why they were kicked, makes them a VIEWER with
+// a zombie snake and ignores whatever else they send.
+func (m *Manager) KickPlayer(playerID int32, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.role != prt.NodeRole_MASTER {
+		return fmt.Errorf("only the master can kick players")
+	}
+	if playerID == m.playerID {
+		return fmt.Errorf("cannot kick yourself")
+	}
+	player := findPlayer(m.players(), playerID)
+	if player == nil {
+		return fmt.Errorf("player %d not found", playerID)
+	}
+	addr, err := playerAddr(player)
+	if err != nil {
+		return fmt.Errorf("resolving player address: %v", err)
+	}
+	message := KickedMessage
+	if reason != "" {
+		message += ": " + reason
+	}
+	m.pending.Drop(addr)
+	m.sendError(addr, message)
+	m.sendRoleChangeMessage(player, prt.NodeRole_VIEWER)
+	if player.Role != prt.NodeRole_VIEWER {
+		m.setPlayerRole(player, prt.NodeRole_VIEWER)
+	}
+	m.banned[normalizeAddr(addr)] = struct{}{}
+	m.dropSessions(playerID)
+	m.kill(player)
+	m.ensureDeputy()
+	log.Printf("Kicked player %s (%d)", player.GetName(), player.GetId())
+	return nil
+}
+
+// handleBanned drops everything a kicked node sends except its acks. The
+// node still watches as a VIEWER, so its traffic keeps it from timing out.
+func (m *Manager) handleBanned(data []byte, addr *net.UDPAddr) {
+	msg, err := decodeMessage(data)
+	if err != nil {
+		return
+	}
+	if m.activityManager != nil {
+		m.activityManager.RecordMessageReceived(addr)
+	}
+	if msg.GetAck() != nil {
+		m.pending.Ack(msg.GetMsgSeq(), addr)
+	}
+}
+
+func (m *Manager) isBanned(addr *net.UDPAddr) bool {
+	_, banned := m.banned[normalizeAddr(addr)]
+	return banned
+}
+
+func (m *Manager) EndGame() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.endGame()
+}
+
+func (m *Manager) endGame() {
+	if m.role != prt.NodeRole_MASTER {
+		return
+	}
+	m.ended = true
+	m.setCanJoin(false)
+	if m.announceTicker != nil {
+		m.announceTicker.Stop()
+		m.announceTicker = nil
+	}
+	m.broadcastNotice(GameEndedMessage)
+	log.Printf("Game ended")
+}
+
+// BroadcastNotice shows text to every other player. The protocol only has
+// ErrorMsg for free text, which every client displays.
+func (m *Manager) BroadcastNotice(text string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.broadcastNotice(text)
+}
+
+func (m *Manager) broadcastNotice(text string) {
+	for _, player := range m.players() {
+		if player.Id == m.playerID {
+			continue
+		}
+		addr, err := playerAddr(player)
+		if err != nil {
+			continue
+		}
+		m.sendError(addr, text)
+	}
+}
+
+func (m *Manager) GameEnded() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ended
+}
@@ -0,0 +1,56 @@
+package network
+
+import (
+	"log"
+	"net"
+	prt "snake-game/internal/proto/gen"
+)
+
+// verifiedSender returns the registered player behind sender_id, but only
+// when the datagram really came from that player's address.
+func (m *Manager) verifiedSender(msg *prt.GameMessage, addr *net.UDPAddr) *prt.GamePlayer {
+	player := findPlayer(m.players(), msg.GetSenderId())
+	if player == nil {
+		return nil
+	}
+	registered, err := playerAddr(player)
+	if err != nil || !sameAddr(registered, addr) {
+		return nil
+	}
+	return player
+}
+
+func (m *Manager) fromMaster(addr *net.UDPAddr) bool {
+	return m.masterAddr != nil && sameAddr(m.masterAddr, addr)
+}
+
+// authorizeRoleChange only lets the master, or a deputy taking over, hand
+// out roles. The one change anyone may make is leaving the game themselves.
+func (m *Manager) authorizeRoleChange(msg *prt.GameMessage, addr *net.UDPAddr) bool {
+	roleChange := msg.GetRoleChange()
+	if m.role == prt.NodeRole_MASTER {
+		return roleChange.GetSenderRole() == prt.NodeRole_VIEWER && m.verifiedSender(msg, addr) != nil
+	}
+	if m.fromMaster(addr) {
+		return true
+	}
+	if roleChange.GetSenderRole() != prt.NodeRole_MASTER {
+		return false
+	}
+	sender := m.verifiedSender(msg, addr)
+	if sender == nil {
+		return false
+	}
+	return sender.GetRole() == prt.NodeRole_DEPUTY || sender.GetRole() == prt.NodeRole_MASTER
+}
+
+func (m *Manager) handleLeave(msg *prt.GameMessage, addr *net.UDPAddr) {
+	player := m.verifiedSender(msg, addr)
+	if player == nil || player.GetId() == m.playerID {
+		return
+	}
+	m.setPlayerRole(player, prt.NodeRole_VIEWER)
+	m.kill(player)
+	m.ensureDeputy()
+	log.Printf("Player %s (%d) left the game", player.GetName(), player.GetId())
+}
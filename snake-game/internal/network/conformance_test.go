@@ -0,0 +1,22 @@
+package network_test
+
+import (
+	"bytes"
+	"snake-game/internal/conformance"
+	"testing"
+)
+
+// TestMasterConformance runs the conformance tool's master checks against
+// a hosted game, so the series never ships failing its own suite.
+func TestMasterConformance(t *testing.T) {
+	_, master := hostGame(t, "127.0.0.1:0")
+	report, err := conformance.CheckMaster(master.LocalAddr())
+	if err != nil {
+		t.Skipf("cannot run the conformance peer: %v", err)
+	}
+	if report.Failed() > 0 {
+		var out bytes.Buffer
+		report.Print(&out)
+		t.Fatalf("conformance failures:\n%s", out.String())
+	}
+}
@@ -0,0 +1,55 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+const directPollInterval = 100 * time.Millisecond
+
+// ConnectTo asks the node at hostport for its games with a unicast
+// DiscoverMsg, for networks where multicast announcements never arrive.
+// hostport may name the host or give an IPv6 literal in brackets. The
+// address keeps being asked on every browse tick until its games stop
+// answering, so they stay in AvailableGames; ConnectTo returns them once
+// the first reply is in.
+func (m *Manager) ConnectTo(hostport string, timeout time.Duration) ([]*GameInfo, error) {
+	addr, err := resolveHostPort(hostport)
+	if err != nil {
+		return nil, err
+	}
+	m.games.AddDirect(addr)
+	m.sendDiscover(addr)
+	ticker := time.NewTicker(directPollInterval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	resend := time.Now().Add(announceInterval)
+	for {
+		select {
+		case <-ticker.C:
+			if games := m.games.GamesAt(addr); len(games) > 0 {
+				return games, nil
+			}
+			if now := time.Now(); now.After(resend) {
+				m.sendDiscover(addr)
+				resend = now.Add(announceInterval)
+			}
+		case <-deadline:
+			return nil, fmt.Errorf("no game answered at %s", addr)
+		case <-m.closeChan:
+			return nil, fmt.Errorf("network manager closed")
+		}
+	}
+}
+
+func resolveHostPort(hostport string) (*net.UDPAddr, error) {
+	addr, err := net.ResolveUDPAddr("udp", hostport)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q: %v", hostport, err)
+	}
+	if addr.Port == 0 {
+		return nil, fmt.Errorf("address %q has no port", hostport)
+	}
+	return addr, nil
+}
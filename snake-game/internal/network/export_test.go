@@ -0,0 +1,25 @@
+package network
+
+import (
+	"google.golang.org/protobuf/proto"
+	"net"
+	prt "snake-game/internal/proto/gen"
+)
+
+// MasterAddr is where this node sends what it owes the master.
+func (m *Manager) MasterAddr() *net.UDPAddr {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.masterAddr
+}
+
+// Players copies the player table as this node knows it.
+func (m *Manager) Players() []*prt.GamePlayer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	players := make([]*prt.GamePlayer, 0)
+	for _, player := range m.players() {
+		players = append(players, proto.Clone(player).(*prt.GamePlayer))
+	}
+	return players
+}
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net"
+	prt "snake-game/internal/proto/gen"
+)
+
+// Some details only mean something between two nodes running this game,
+// like whether the map wraps around. Walls need none of this: every client
+// gets them as ZOMBIE snakes without a player. The details
+// travel in extension datagrams of their own instead of protocol messages.
+// The magic prefix is not a valid GameMessage, so other implementations
+// drop them as malformed and only ever see plain protocol traffic.
+const extensionMagic = "\xffsnake-game\n"
+
+type extension struct {
+	// Hello announces that the sender understands extensions. The master
+	// answers it with the details of its game.
+	Hello  bool `json:"hello,omitempty"`
+	NoWrap bool `json:"no_wrap,omitempty"`
+	// Token is a rejoin token: handed out by the master, and sent back in
+	// the hello before a JoinMsg to reclaim the player it belongs to.
+	Token string `json:"token,omitempty"`
+}
+
+func isExtension(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(extensionMagic))
+}
+
+func (m *Manager) sendExtension(ext *extension, addr *net.UDPAddr) {
+	data, err := json.Marshal(ext)
+	if err != nil {
+		log.Printf("Error marshaling extension: %v", err)
+		return
+	}
+	if err := m.send(append([]byte(extensionMagic), data...), addr); err != nil {
+		log.Printf("Error sending extension to %s: %v", addr, err)
+	}
+}
+
+// sendHello goes to every master we join or that takes over the game.
+func (m *Manager) sendHello(addr *net.UDPAddr) {
+	m.sendExtension(&extension{Hello: true}, addr)
+}
+
+func (m *Manager) handleExtension(data []byte, addr *net.UDPAddr) {
+	var ext extension
+	if err := json.Unmarshal(data[len(extensionMagic):], &ext); err != nil {
+		log.Printf("Rejected extension from %s: %v", addr, err)
+		return
+	}
+	m.extPeers[normalizeAddr(addr)] = struct{}{}
+	switch {
+	case ext.Hello && m.role == prt.NodeRole_MASTER:
+		if ext.Token != "" {
+			m.joinTokens[normalizeAddr(addr)] = ext.Token
+		}
+		m.sendExtension(&extension{NoWrap: m.gameNoWrap()}, addr)
+	case !ext.Hello && m.fromMaster(addr):
+		m.noWrap.Store(ext.NoWrap)
+		if ext.Token != "" {
+			m.saveSession(ext.Token)
+		}
+	}
+}
+
+// speaksExtension reports whether addr has sent us an extension, and so
+// knows what to do with the next one.
+func (m *Manager) speaksExtension(addr *net.UDPAddr) bool {
+	_, ok := m.extPeers[normalizeAddr(addr)]
+	return ok
+}
+
+func (m *Manager) gameNoWrap() bool {
+	if lgc := m.gameLogic(); lgc != nil {
+		return lgc.NoWrap()
+	}
+	return false
+}
+
+// NoWrap reports whether the master of the game we play in said its map
+// does not wrap around. A deputy carries it on when it takes over. It does
+// not take mu, so a RoleListener may call it.
+func (m *Manager) NoWrap() bool {
+	return m.noWrap.Load()
+}
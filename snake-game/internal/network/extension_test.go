@@ -0,0 +1,84 @@
+package network_test
+
+import (
+	"fmt"
+	"snake-game/internal/conformance"
+	"snake-game/internal/game/headless"
+	"snake-game/internal/game/logic"
+	prt "snake-game/internal/proto/gen"
+	"sync"
+	"testing"
+	"time"
+)
+
+type lastState struct {
+	mu    sync.Mutex
+	state *prt.GameState
+}
+
+func (l *lastState) OnGameStateReceived(state *prt.GameState) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.state = state
+}
+
+func (l *lastState) get() *prt.GameState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+func countWalls(state *prt.GameState) int {
+	walls := 0
+	for _, snake := range state.GetSnakes() {
+		if logic.IsWallSnake(snake) {
+			walls++
+		}
+	}
+	return walls
+}
+
+// TestMapReachesClients hosts a walled map that does not wrap. A client of
+// this game learns both; a client that only speaks the protocol still gets
+// the walls as snakes, so it can see what it would die on.
+func TestMapReachesClients(t *testing.T) {
+	mgr := newManager(t, "127.0.0.1:0")
+	host := headless.NewHost("walled", "master", testConfig(), mgr)
+	host.SetMap(&logic.Map{
+		Name: "walled", Width: 30, Height: 30, NoWrap: true,
+		Walls: []*prt.GameState_Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 10, Y: 0}},
+	})
+	host.Start()
+	t.Cleanup(host.Stop)
+	addr := fmt.Sprintf("127.0.0.1:%d", mgr.LocalAddr().Port)
+
+	client := newManager(t, "127.0.0.1:0")
+	states := &lastState{}
+	client.SetGameStateListener(states)
+	join(t, client, addr, "own", prt.NodeRole_VIEWER)
+	eventually(t, "the client learns the map does not wrap", client.NoWrap)
+	eventually(t, "the client sees both walls", func() bool { return countWalls(states.get()) == 2 })
+	for _, snake := range states.get().GetSnakes() {
+		if logic.IsWallSnake(snake) && snake.GetPoints()[0].GetY() != 0 {
+			t.Errorf("wall %d decoded at %v", snake.GetPlayerId(), snake.GetPoints())
+		}
+	}
+
+	peer, err := conformance.NewPeer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+	peer.Send(&prt.GameMessage{Type: &prt.GameMessage_Join{Join: &prt.GameMessage_JoinMsg{
+		PlayerName: "foreign", GameName: "walled", RequestedRole: prt.NodeRole_VIEWER,
+	}}}, mgr.LocalAddr())
+	received := peer.Collect(5*testDelayMs*time.Millisecond, func(r *conformance.Received) bool { return r.Msg.GetState() != nil })
+	if len(received) == 0 {
+		t.Fatal("foreign client got no states")
+	}
+	for _, r := range received {
+		if walls := countWalls(r.Msg.GetState().GetState()); walls != 2 {
+			t.Fatalf("foreign client got %d wall snakes, want 2", walls)
+		}
+	}
+}
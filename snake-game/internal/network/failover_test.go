@@ -0,0 +1,93 @@
+package network_test
+
+import (
+	"fmt"
+	"net"
+	"snake-game/internal/game/headless"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"testing"
+)
+
+// failoverGame is a hosted game with a deputy and a NORMAL player, the
+// setup of every failover case in the README. Nodes leave by closing their
+// manager, which says nothing to the others, the way a crashed node does.
+type failoverGame struct {
+	host   *headless.Host
+	master *network.Manager
+	deputy *network.Manager
+	normal *network.Manager
+	ids    map[*network.Manager]int32
+}
+
+func newFailoverGame(t *testing.T) *failoverGame {
+	t.Helper()
+	host, master := hostGame(t, "127.0.0.1:0")
+	addr := fmt.Sprintf("127.0.0.1:%d", master.LocalAddr().Port)
+	g := &failoverGame{host: host, master: master, ids: make(map[*network.Manager]int32)}
+	g.deputy = newManager(t, "127.0.0.1:0")
+	g.ids[g.deputy] = join(t, g.deputy, addr, "deputy", prt.NodeRole_NORMAL)
+	g.normal = newManager(t, "127.0.0.1:0")
+	g.ids[g.normal] = join(t, g.normal, addr, "normal", prt.NodeRole_NORMAL)
+	eventually(t, "the first player is the deputy", func() bool { return g.deputy.GetRole() == prt.NodeRole_DEPUTY })
+	eventually(t, "both players know the whole table", func() bool {
+		return len(g.deputy.Players()) == 3 && len(g.normal.Players()) == 3 &&
+			g.role(g.normal, g.deputy) == prt.NodeRole_DEPUTY
+	})
+	return g
+}
+
+// role is the role of player as observer last heard it.
+func (g *failoverGame) role(observer, player *network.Manager) prt.NodeRole {
+	if p := findPlayer(observer.Players(), g.ids[player]); p != nil {
+		return p.GetRole()
+	}
+	return prt.NodeRole(-1)
+}
+
+func sameNode(addr *net.UDPAddr, node *network.Manager) bool {
+	return addr != nil && addr.IP.Equal(node.LocalAddr().IP) && addr.Port == node.LocalAddr().Port
+}
+
+// TestNormalSwitchesToDeputy is case a: a NORMAL player that loses the
+// master sends everything to the deputy from then on.
+func TestNormalSwitchesToDeputy(t *testing.T) {
+	g := newFailoverGame(t)
+	g.host.Stop()
+	g.master.Close()
+	eventually(t, "the NORMAL player talks to the deputy", func() bool {
+		return sameNode(g.normal.MasterAddr(), g.deputy)
+	})
+	if err := g.normal.SendSteer(prt.Direction_LEFT); err != nil {
+		t.Fatalf("steering after the failover: %v", err)
+	}
+}
+
+// TestMasterReplacesDeputy is case b: a master that loses its deputy picks
+// a NORMAL player and tells it with a RoleChangeMsg.
+func TestMasterReplacesDeputy(t *testing.T) {
+	g := newFailoverGame(t)
+	g.deputy.Close()
+	eventually(t, "the master makes the NORMAL player its deputy", func() bool {
+		player := findPlayer(g.host.Players(), g.ids[g.normal])
+		return player != nil && player.GetRole() == prt.NodeRole_DEPUTY
+	})
+	if player := findPlayer(g.host.Players(), g.ids[g.deputy]); player == nil || player.GetRole() != prt.NodeRole_VIEWER {
+		t.Errorf("the lost deputy is %v, want a VIEWER", player)
+	}
+	eventually(t, "the new deputy is told", func() bool { return g.normal.GetRole() == prt.NodeRole_DEPUTY })
+}
+
+// TestDeputyTakesOver is case c: a deputy that loses the master becomes the
+// master, elects a new deputy and tells every player.
+func TestDeputyTakesOver(t *testing.T) {
+	g := newFailoverGame(t)
+	g.host.Stop()
+	g.master.Close()
+	eventually(t, "the deputy takes over", func() bool { return g.deputy.GetRole() == prt.NodeRole_MASTER })
+	eventually(t, "the NORMAL player becomes the new deputy", func() bool { return g.normal.GetRole() == prt.NodeRole_DEPUTY })
+	eventually(t, "the new deputy knows the new master", func() bool { return sameNode(g.normal.MasterAddr(), g.deputy) })
+	if role := g.role(g.deputy, g.normal); role != prt.NodeRole_DEPUTY {
+		t.Errorf("the new master has the NORMAL player as %v, want DEPUTY", role)
+	}
+}
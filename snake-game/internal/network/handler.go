@@ -9,12 +9,31 @@ import (
 	prt "snake-game/internal/proto/gen"
 )
 
-var firstPlayer = true
-
 func (m *Manager) handleMessage(data []byte, addr *net.UDPAddr) {
-	var msg prt.GameMessage
-	if err := proto.Unmarshal(data, &msg); err != nil {
-		log.Printf("Error unmarshaling message: %v", err)
+	unlock := m.lock()
+	defer unlock()
+	m.metrics.Received.Add(1)
+	m.rememberZone(addr)
+	if m.isBanned(addr) {
+		m.handleBanned(data, addr)
+		return
+	}
+	if isExtension(data) {
+		m.handleExtension(data, addr)
+		return
+	}
+	msg, err := decodeMessage(data)
+	if err != nil {
+		m.metrics.Invalid.Add(1)
+		log.Printf("Rejected datagram from %s: %v", addr, err)
+		return
+	}
+	if err := validateMessage(msg, m.gameConfig(), m.players()); err != nil {
+		m.metrics.Invalid.Add(1)
+		log.Printf("Rejected message from %s: %v", addr, err)
+		return
+	}
+	if !m.allow(msg, addr) {
 		return
 	}
 	shouldTrackActivity := true
@@ -25,33 +44,38 @@ func (m *Manager) handleMessage(data []byte, addr *net.UDPAddr) {
 	if shouldTrackActivity && m.activityManager != nil {
 		m.activityManager.RecordMessageReceived(addr)
 	}
+	if needsAck(msg) {
+		if err := m.sendAck(msg.GetMsgSeq(), msg.GetSenderId(), addr); err != nil {
+			log.Printf("Error acking message from %s: %v", addr, err)
+		}
+	}
 	switch {
 	case msg.GetPing() != nil:
-		m.handlePing(&msg, addr)
+		m.handlePing(msg, addr)
 
 	case msg.GetSteer() != nil:
-		m.handleSteer(&msg)
+		m.handleSteer(msg, addr)
 
 	case msg.GetAck() != nil:
-		m.handleAck(&msg, addr)
+		m.handleAck(msg, addr)
 
 	case msg.GetState() != nil:
-		m.handleState(&msg)
+		m.handleState(msg, addr)
 
 	case msg.GetAnnouncement() != nil:
-		m.handleAnnouncement(&msg, addr)
+		m.handleAnnouncement(msg, addr)
 
 	case msg.GetJoin() != nil:
-		m.handleJoin(&msg, addr)
+		m.handleJoin(msg, addr)
 
 	case msg.GetError() != nil:
-		m.handleError(&msg)
+		m.handleError(msg)
 
 	case msg.GetRoleChange() != nil:
-		m.handleRoleChange(&msg)
+		m.handleRoleChange(msg, addr)
 
 	case msg.GetDiscover() != nil:
-		m.handleDiscovery(&msg, addr)
+		m.handleDiscovery(msg, addr)
 
 	default:
 		log.Printf("Unknown message type from %s", addr)
@@ -60,7 +84,7 @@ func (m *Manager) handleMessage(data []byte, addr *net.UDPAddr) {
 
 func (m *Manager) handlePing(msg *prt.GameMessage, addr *net.UDPAddr) {}
 
-func (m *Manager) handleSteer(msg *prt.GameMessage) {
+func (m *Manager) handleSteer(msg *prt.GameMessage, addr *net.UDPAddr) {
 	if m.role != prt.NodeRole_MASTER {
 		return
 	}
@@ -73,17 +97,15 @@ func (m *Manager) handleSteer(msg *prt.GameMessage) {
 		return
 	}
 	direction := steerMsg.GetDirection()
-	playerExists := false
-	for _, player := range m.gameAnnounce.GetPlayers().GetPlayers() {
-		if player.GetId() == senderID {
-			playerExists = true
-			break
-		}
-	}
-	if !playerExists {
+	if findPlayer(m.players(), senderID) == nil {
 		log.Printf("Steer message from unknown player ID: %d", senderID)
 		return
 	}
+	if m.verifiedSender(msg, addr) == nil {
+		m.metrics.SpoofedSteers.Add(1)
+		log.Printf("Rejected steer for player %d from %s: address does not match", senderID, addr)
+		return
+	}
 	if m.steerListener != nil {
 		err := m.steerListener.OnSteerReceived(senderID, direction)
 		if err != nil {
@@ -93,22 +115,34 @@ func (m *Manager) handleSteer(msg *prt.GameMessage) {
 }
 
 func (m *Manager) handleAck(msg *prt.GameMessage, addr *net.UDPAddr) {
-	ackMsg := msg.GetAck()
-	if ackMsg == nil {
+	if m.pending.Ack(msg.GetMsgSeq(), addr) {
 		return
 	}
-	if msg.GetReceiverId() != 0 {
-		m.playerID = msg.GetReceiverId()
-		if m.JoinNotify != nil {
-			m.JoinNotify <- m.playerID
-		}
-		log.Printf("Successfully joined the game! Player ID: %d", m.playerID)
-	} else {
-		log.Printf("Received ACK for message seq %d from %s", msg.GetMsgSeq(), addr)
+	if !m.joining || msg.GetMsgSeq() != m.joinSeq || msg.GetReceiverId() == 0 {
+		return
+	}
+	m.playerID = msg.GetReceiverId()
+	m.joining = false
+	m.role = m.joinRole
+	if m.joinNotify != nil {
+		m.joinNotify <- m.playerID
+		m.joinNotify = nil
 	}
+	log.Printf("Successfully joined the game! Player ID: %d", m.playerID)
 }
 
 func (m *Manager) handleDiscovery(msg *prt.GameMessage, addr *net.UDPAddr) {
+	if m.role != prt.NodeRole_MASTER {
+		return
+	}
+	data, err := m.announcementData()
+	if err != nil {
+		log.Printf("Error marshaling announcement: %v", err)
+		return
+	}
+	if _, err = m.unicastConn.WriteToUDP(data, addr); err != nil {
+		log.Printf("Error answering discover from %s: %v", addr, err)
+	}
 }
 
 func (m *Manager) handleJoin(msg *prt.GameMessage, addr *net.UDPAddr) {
@@ -116,25 +150,29 @@ func (m *Manager) handleJoin(msg *prt.GameMessage, addr *net.UDPAddr) {
 		return
 	}
 	joinMsg := msg.GetJoin()
-	lgc := m.joinListener.GetLogic()
-	if !lgc.CanPlaceSnake() {
-		m.gameAnnounce.CanJoin = false
-		errorMsg := &prt.GameMessage_ErrorMsg{
-			ErrorMessage: "Cannot find suitable position for new snake",
-		}
-		message := &prt.GameMessage{
-			MsgSeq: msg.GetMsgSeq(),
-			Type:   &prt.GameMessage_Error{Error: errorMsg},
-		}
-		data, err := proto.Marshal(message)
-		if err != nil {
-			log.Printf("Error marshaling error message: %v", err)
+	lgc := m.gameLogic()
+	if lgc == nil || m.activityManager == nil {
+		log.Printf("Rejected join from %s: game is not ready", addr)
+		return
+	}
+	name := joinMsg.GetPlayerName()
+	token := m.takeJoinToken(addr)
+	if player := m.playerAt(addr); player != nil {
+		m.promotePlayer(player, msg, addr)
+		return
+	}
+	if token != "" {
+		if s := m.claimSession(name, token); s != nil && m.reclaimPlayer(s, msg, addr) {
 			return
 		}
-		_, err = m.unicastConn.WriteToUDP(data, addr)
-		if err != nil {
-			log.Printf("Error sending error message: %v", err)
-		}
+	}
+	if !m.gameAnnounce.GetCanJoin() {
+		m.sendError(addr, NotJoinableMessage)
+		return
+	}
+	if !lgc.CanPlaceSnake() {
+		m.gameAnnounce.CanJoin = false
+		m.sendError(addr, FieldFullMessage)
 		return
 	}
 	m.activityManager.AddNodeToMonitor(addr)
@@ -150,16 +188,8 @@ func (m *Manager) handleJoin(msg *prt.GameMessage, addr *net.UDPAddr) {
 		}
 		newPlayerID = logic.GeneratePlayerID()
 	}
-	var player *prt.GamePlayer
-	if firstPlayer {
-		player = &prt.GamePlayer{
-			Name: joinMsg.PlayerName, Id: newPlayerID, Type: joinMsg.PlayerType, Role: prt.NodeRole_DEPUTY, Score: 0, IpAddress: addr.IP.String(), Port: int32(addr.Port),
-		}
-		firstPlayer = false
-	} else {
-		player = &prt.GamePlayer{
-			Name: joinMsg.PlayerName, Id: newPlayerID, Type: joinMsg.PlayerType, Role: joinMsg.RequestedRole, Score: 0, IpAddress: addr.IP.String(), Port: int32(addr.Port),
-		}
+	player := &prt.GamePlayer{
+		Name: name, Id: newPlayerID, Type: joinMsg.PlayerType, Role: joinMsg.RequestedRole, Score: 0, IpAddress: hostString(addr), Port: int32(addr.Port),
 	}
 	m.joinListener.OnGameAddPlayer(player)
 	message := &prt.GameMessage{MsgSeq: msg.GetMsgSeq(), Type: &prt.GameMessage_Ack{Ack: ackMsg}, ReceiverId: newPlayerID}
@@ -171,49 +201,129 @@ func (m *Manager) handleJoin(msg *prt.GameMessage, addr *net.UDPAddr) {
 	if err != nil {
 		fmt.Printf("Error writing message: %v", err)
 	}
+	m.sendSession(m.issueSession(newPlayerID, name), addr)
+	m.ensureDeputy()
+}
+
+// reclaimPlayer moves a returning player to the address they rejoin from.
+// A snake they lost by disconnecting comes back to life; otherwise they ask
+// for a snake like any viewer.
+func (m *Manager) reclaimPlayer(s *session, msg *prt.GameMessage, addr *net.UDPAddr) bool {
+	player := findPlayer(m.players(), s.playerID)
+	if player == nil {
+		return false
+	}
+	player.IpAddress = hostString(addr)
+	player.Port = int32(addr.Port)
+	m.activityManager.AddNodeToMonitor(addr)
+	if player.GetRole() == prt.NodeRole_VIEWER && msg.GetJoin().GetRequestedRole() == prt.NodeRole_NORMAL &&
+		m.gameLogic().ReclaimSnake(player.GetId()) {
+		m.setPlayerRole(player, prt.NodeRole_NORMAL)
+	}
+	m.promotePlayer(player, msg, addr)
+	m.sendSession(s.token, addr)
+	log.Printf("Player %s (%d) rejoined as %s with score %d", player.GetName(), player.GetId(), player.GetRole(), player.GetScore())
+	return true
 }
 
-func (m *Manager) handleState(msg *prt.GameMessage) {
+func (m *Manager) handleState(msg *prt.GameMessage, addr *net.UDPAddr) {
+	if m.role == prt.NodeRole_MASTER || m.masterAddr != nil && !m.fromMaster(addr) {
+		return
+	}
 	gameState := msg.GetState().State
+	if gameState.GetStateOrder() <= m.lastStateOrder {
+		return
+	}
+	cfg := m.gameConfig()
+	gameState = logic.DecodeState(gameState, cfg.GetWidth(), cfg.GetHeight())
+	m.lastStateOrder = gameState.GetStateOrder()
+	m.syncPlayers(gameState.GetPlayers())
 	if m.stateListener != nil {
 		m.stateListener.OnGameStateReceived(gameState)
 	}
 }
 
 func (m *Manager) handleAnnouncement(msg *prt.GameMessage, addr *net.UDPAddr) {
-	games := msg.GetAnnouncement().GetGames()
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	for _, game := range games {
-		if m.AvailableGames == nil {
-			m.AvailableGames = make(map[string]*GameInfo)
+	m.games.Observe(msg.GetAnnouncement().GetGames(), addr, true)
+}
+
+func (m *Manager) handleMulticastMessage(data []byte, addr *net.UDPAddr) {
+	msg, err := decodeMessage(data)
+	if err != nil {
+		log.Printf("Rejected multicast datagram from %s: %v", addr, err)
+		return
+	}
+	switch {
+	case msg.GetAnnouncement() != nil:
+		if err := validateMessage(msg, nil, nil); err != nil {
+			log.Printf("Rejected announcement from %s: %v", addr, err)
+			return
 		}
-		m.AvailableGames[game.GameName] = &GameInfo{
-			Announcement: game,
-			MasterAddr:   addr,
+		m.games.Observe(msg.GetAnnouncement().GetGames(), addr, false)
+	case msg.GetDiscover() != nil:
+		m.metrics.Received.Add(1)
+		if !m.allow(msg, addr) {
+			return
 		}
-	}
-	if m.gameListener != nil {
-		m.gameListener.OnGameAnnouncement(games)
+		unlock := m.lock()
+		defer unlock()
+		m.handleDiscovery(msg, addr)
 	}
 }
 
 func (m *Manager) handleError(msg *prt.GameMessage) {
+	message := msg.GetError().GetErrorMessage()
+	// An error while joining is the master's answer: stop resending the join.
+	m.joining = false
+	switch {
+	case message == GameEndedMessage:
+		m.ended = true
+	case IsKickMessage(message):
+		m.ended = true
+		m.role = prt.NodeRole_VIEWER
+	}
+	if m.errorListener != nil {
+		m.errorListener.OnErrorReceived(message)
+		return
+	}
 	fmt.Println(msg.GetError())
 }
 
-func (m *Manager) handleRoleChange(msg *prt.GameMessage) {
+func (m *Manager) handleRoleChange(msg *prt.GameMessage, addr *net.UDPAddr) {
 	roleChangeMsg := msg.GetRoleChange()
 	if roleChangeMsg == nil {
 		return
 	}
+	if !m.authorizeRoleChange(msg, addr) {
+		m.metrics.SpoofedRoleChange.Add(1)
+		log.Printf("Rejected role change from %s (sender %d)", addr, msg.GetSenderId())
+		return
+	}
+	if m.role == prt.NodeRole_MASTER {
+		m.handleLeave(msg, addr)
+		return
+	}
 	receiverRole := roleChangeMsg.GetReceiverRole()
-	var ourPlayer *prt.GamePlayer
-	for _, player := range m.gameAnnounce.GetPlayers().GetPlayers() {
-		if player.GetId() == m.playerID {
-			ourPlayer = player
-			break
+	if msg.GetReceiverId() != 0 && msg.GetReceiverId() != m.playerID {
+		log.Printf("Rejected role change addressed to player %d", msg.GetReceiverId())
+		return
+	}
+	ourPlayer := findPlayer(m.players(), m.playerID)
+	if ourPlayer == nil {
+		log.Printf("Rejected role change: player %d is not in the game", m.playerID)
+		return
+	}
+	if receiverRole == prt.NodeRole_MASTER {
+		m.takeOver(findPlayer(m.players(), msg.GetSenderId()))
+		return
+	}
+	if roleChangeMsg.GetSenderRole() == prt.NodeRole_MASTER && !m.fromMaster(addr) {
+		m.masterAddr = addr
+		m.retargetPending(addr)
+		if m.activityManager != nil {
+			m.activityManager.AddNodeToMonitor(addr)
 		}
+		m.sendHello(addr)
 	}
-	m.ChangeRole(ourPlayer, receiverRole)
+	m.setPlayerRole(ourPlayer, receiverRole)
 }
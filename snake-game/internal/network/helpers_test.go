@@ -0,0 +1,98 @@
+package network_test
+
+import (
+	"io"
+	"log"
+	"os"
+	"snake-game/internal/game/headless"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"testing"
+	"time"
+)
+
+const (
+	testGroup   = "239.255.255.250:0"
+	testDelayMs = 200
+	waitFor     = 5 * time.Second
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv("NETWORK_TEST_LOGS") == "" {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+func testConfig() *prt.GameConfig {
+	return &prt.GameConfig{Width: 30, Height: 30, FoodStatic: 1, StateDelayMs: testDelayMs}
+}
+
+// newManager starts a manager bound to listen, e.g. "127.0.0.1:0", on a
+// private multicast port so tests never see each other's games.
+func newManager(t *testing.T, listen string) *network.Manager {
+	t.Helper()
+	mgr := network.NewNetworkManager(prt.NodeRole_NORMAL, nil)
+	mgr.SetMulticastGroup(testGroup)
+	mgr.SetMulticastInterface("")
+	mgr.SetListenAddr(listen)
+	if err := mgr.Start(); err != nil {
+		t.Skipf("cannot open sockets on %s: %v", listen, err)
+	}
+	t.Cleanup(mgr.Close)
+	return mgr
+}
+
+func hostGame(t *testing.T, listen string) (*headless.Host, *network.Manager) {
+	t.Helper()
+	mgr := newManager(t, listen)
+	host := headless.NewHost("loopback", "master", testConfig(), mgr)
+	host.Start()
+	t.Cleanup(host.Stop)
+	return host, mgr
+}
+
+// join connects client to the master at addr and joins its game with role,
+// the way the lobby does.
+func join(t *testing.T, client *network.Manager, addr string, name string, role prt.NodeRole) int32 {
+	t.Helper()
+	games, err := client.ConnectTo(addr, waitFor)
+	if err != nil {
+		t.Fatalf("connecting to %s: %v", addr, err)
+	}
+	info := games[0]
+	joined, err := client.SendJoinRequest(prt.PlayerType_ROBOT, name, info, role)
+	if err != nil {
+		t.Fatalf("sending join: %v", err)
+	}
+	select {
+	case id := <-joined:
+		client.SetGameAnnouncement(info.Announcement)
+		client.SetActivityManager(info.Announcement.GetConfig().GetStateDelayMs())
+		return id
+	case <-time.After(waitFor):
+		t.Fatalf("%s got no answer to its join", name)
+	}
+	return 0
+}
+
+// eventually polls cond until it holds or the wait runs out.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(waitFor)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func findPlayer(players []*prt.GamePlayer, id int32) *prt.GamePlayer {
+	for _, player := range players {
+		if player.GetId() == id {
+			return player
+		}
+	}
+	return nil
+}
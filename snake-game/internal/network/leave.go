@@ -0,0 +1,93 @@
+package network
+
+import (
+	"log"
+	prt "snake-game/internal/proto/gen"
+)
+
+const MasterLostMessage = "Master left and no deputy took over"
+
+// syncPlayers adopts the player list from the master's latest state, so a
+// non-master node knows the current deputy and its own role.
+func (m *Manager) syncPlayers(players *prt.GamePlayers) {
+	if m.gameAnnounce == nil || players == nil {
+		return
+	}
+	m.gameAnnounce.Players = players
+	if own := findPlayer(players.GetPlayers(), m.playerID); own != nil && own.Role != prt.NodeRole_MASTER {
+		m.setOwnRole(own.Role)
+	}
+}
+
+func (m *Manager) monitorPlayers() {
+	if m.activityManager == nil {
+		return
+	}
+	for _, p := range m.players() {
+		if p.Id == m.playerID {
+			continue
+		}
+		if addr, err := playerAddr(p); err == nil {
+			m.activityManager.AddNodeToMonitor(addr)
+		}
+	}
+}
+
+// LeaveGame tells the other nodes we are going and drops all per-game state,
+// leaving the sockets and game browser running for the next game.
+func (m *Manager) LeaveGame() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.gameAnnounce != nil && !m.ended {
+		switch {
+		case m.role == prt.NodeRole_MASTER:
+			if deputy := m.deputy(); deputy != nil {
+				m.sendRoleChangeMessage(deputy, prt.NodeRole_MASTER)
+			} else {
+				m.endGame()
+			}
+		case m.masterAddr != nil:
+			m.sendLeave()
+		}
+	}
+	m.stopGame()
+}
+
+func (m *Manager) sendLeave() {
+	msg := &prt.GameMessage{
+		SenderId: m.playerID,
+		Type: &prt.GameMessage_RoleChange{RoleChange: &prt.GameMessage_RoleChangeMsg{
+			SenderRole:   prt.NodeRole_VIEWER,
+			ReceiverRole: prt.NodeRole_MASTER,
+		}},
+	}
+	if err := m.sendReliable(msg, m.masterAddr, true); err != nil {
+		log.Printf("Error sending leave message: %v", err)
+	}
+}
+
+func (m *Manager) stopGame() {
+	close(m.gameDone)
+	m.gameDone = make(chan struct{})
+	if m.announceTicker != nil {
+		m.announceTicker.Stop()
+		m.announceTicker = nil
+	}
+	if m.activityManager != nil {
+		m.activityManager.Close()
+		m.activityManager = nil
+	}
+	m.banned = make(map[string]struct{})
+	m.sessions = make(map[string]*session)
+	m.joinTokens = make(map[string]string)
+	m.role = prt.NodeRole_NORMAL
+	m.gameAnnounce = nil
+	m.masterAddr = nil
+	m.playerID = 0
+	m.ended = false
+	m.lastStateOrder = -1
+	m.pending.Drop(nil)
+	m.joining = false
+	m.joinNotify = nil
+	m.join = nil
+}
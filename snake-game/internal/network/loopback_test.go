@@ -0,0 +1,188 @@
+package network_test
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"net"
+	"snake-game/internal/conformance"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"testing"
+	"time"
+)
+
+// TestJoinOverLoopback plays the join flow on each loopback address family,
+// including an IPv4 client talking to a dual-stack master, whose socket sees
+// it as an IPv4-mapped IPv6 address.
+func TestJoinOverLoopback(t *testing.T) {
+	tests := []struct {
+		name         string
+		masterListen string
+		clientListen string
+		masterHost   string
+		wantIP       string
+	}{
+		{"ipv4", "127.0.0.1:0", "127.0.0.1:0", "127.0.0.1", "127.0.0.1"},
+		{"ipv6", "[::1]:0", "[::1]:0", "::1", "::1"},
+		{"dual-stack", "[::]:0", "127.0.0.1:0", "127.0.0.1", "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, master := hostGame(t, tt.masterListen)
+			client := newManager(t, tt.clientListen)
+			addr := fmt.Sprintf("[%s]:%d", tt.masterHost, master.LocalAddr().Port)
+			id := join(t, client, addr, "player", prt.NodeRole_NORMAL)
+
+			player := findPlayer(host.Players(), id)
+			if player == nil {
+				t.Fatalf("player %d is not in the master's table", id)
+			}
+			if player.GetIpAddress() != tt.wantIP || int(player.GetPort()) != client.LocalAddr().Port {
+				t.Errorf("player registered at %s:%d, want %s:%d", player.GetIpAddress(), player.GetPort(), tt.wantIP, client.LocalAddr().Port)
+			}
+			eventually(t, "the client sees itself in a state", func() bool {
+				return client.GetRole() == prt.NodeRole_NORMAL && client.GetID() == id
+			})
+		})
+	}
+}
+
+// TestTimeoutOverLoopback checks that a master finds the player behind a
+// timed-out address even when the socket reports it in a different form.
+func TestTimeoutOverLoopback(t *testing.T) {
+	host, master := hostGame(t, "[::]:0")
+	client := newManager(t, "127.0.0.1:0")
+	id := join(t, client, fmt.Sprintf("127.0.0.1:%d", master.LocalAddr().Port), "player", prt.NodeRole_NORMAL)
+	client.Close()
+	eventually(t, "the master demotes the silent player", func() bool {
+		player := findPlayer(host.Players(), id)
+		return player != nil && player.GetRole() == prt.NodeRole_VIEWER
+	})
+}
+
+// TestKickOverLoopback checks that a kicked player is left watching with a
+// zombie snake, leaves, and cannot get back in from the same address.
+func TestKickOverLoopback(t *testing.T) {
+	host, master := hostGame(t, "127.0.0.1:0")
+	client := newManager(t, "127.0.0.1:0")
+	addr := fmt.Sprintf("127.0.0.1:%d", master.LocalAddr().Port)
+	id := join(t, client, addr, "player", prt.NodeRole_NORMAL)
+	if err := host.Kick(fmt.Sprint(id), "test"); err != nil {
+		t.Fatal(err)
+	}
+	if player := findPlayer(host.Players(), id); player == nil || player.GetRole() != prt.NodeRole_VIEWER {
+		t.Fatalf("kicked player is %v, want a VIEWER", player)
+	}
+	eventually(t, "the client learns it was kicked", client.GameEnded)
+
+	client.LeaveGame()
+	games, err := client.ConnectTo(addr, waitFor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined, err := client.SendJoinRequest(prt.PlayerType_ROBOT, "player", games[0], prt.NodeRole_NORMAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case id := <-joined:
+		t.Fatalf("kicked client joined again as player %d", id)
+	case <-time.After(3 * testDelayMs * time.Millisecond):
+	}
+	if len(host.Players()) != 2 {
+		t.Fatalf("master has %d players after the rejoin", len(host.Players()))
+	}
+}
+
+// TestKickReachesPlainClient checks that a client that only speaks the
+// protocol is told it is a VIEWER, and its snake becomes a zombie.
+func TestKickReachesPlainClient(t *testing.T) {
+	host, master := hostGame(t, "127.0.0.1:0")
+	peer, err := conformance.NewPeer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+	seq, _ := peer.Send(&prt.GameMessage{Type: &prt.GameMessage_Join{Join: &prt.GameMessage_JoinMsg{
+		PlayerName: "troll", GameName: "loopback", RequestedRole: prt.NodeRole_NORMAL,
+	}}}, master.LocalAddr())
+	ack, err := peer.Expect(waitFor, func(r *conformance.Received) bool { return r.Msg.GetAck() != nil && r.Msg.GetMsgSeq() == seq })
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := ack.Msg.GetReceiverId()
+	peer.SetPlayerID(id)
+	if err := host.Kick(fmt.Sprint(id), "test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := peer.Expect(waitFor, func(r *conformance.Received) bool {
+		return r.Msg.GetRoleChange().GetReceiverRole() == prt.NodeRole_VIEWER
+	}); err != nil {
+		t.Fatalf("no role change to VIEWER: %v", err)
+	}
+	if _, err := peer.Expect(waitFor, func(r *conformance.Received) bool {
+		for _, snake := range r.Msg.GetState().GetState().GetSnakes() {
+			if snake.GetPlayerId() == id {
+				return snake.GetState() == prt.GameState_Snake_ZOMBIE
+			}
+		}
+		return false
+	}); err != nil {
+		t.Fatalf("kicked snake is not a zombie: %v", err)
+	}
+}
+
+// TestJoinIsResent plays a master that loses the first JoinMsg: the client
+// sends it again under the same msg_seq and joins once that one is acked.
+func TestJoinIsResent(t *testing.T) {
+	master, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skipf("cannot open a socket: %v", err)
+	}
+	defer master.Close()
+	client := newManager(t, "127.0.0.1:0")
+	info := &network.GameInfo{
+		Announcement: &prt.GameAnnouncement{GameName: "lossy", Config: testConfig(), CanJoin: true},
+		MasterAddr:   master.LocalAddr().(*net.UDPAddr),
+	}
+	joined, err := client.SendJoinRequest(prt.PlayerType_ROBOT, "player", info, prt.NodeRole_NORMAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readJoin := func() (*prt.GameMessage, *net.UDPAddr) {
+		t.Helper()
+		buf := make([]byte, 4096)
+		master.SetReadDeadline(time.Now().Add(waitFor))
+		for {
+			n, addr, err := master.ReadFromUDP(buf)
+			if err != nil {
+				t.Fatalf("no join arrived: %v", err)
+			}
+			msg := &prt.GameMessage{}
+			if proto.Unmarshal(buf[:n], msg) == nil && msg.GetJoin() != nil {
+				return msg, addr
+			}
+		}
+	}
+	first, _ := readJoin()
+	again, from := readJoin()
+	if again.GetMsgSeq() != first.GetMsgSeq() {
+		t.Fatalf("join resent as msg_seq %d, want %d", again.GetMsgSeq(), first.GetMsgSeq())
+	}
+	ack, _ := proto.Marshal(&prt.GameMessage{
+		MsgSeq: again.GetMsgSeq(), ReceiverId: 7,
+		Type: &prt.GameMessage_Ack{Ack: &prt.GameMessage_AckMsg{}},
+	})
+	if _, err := master.WriteToUDP(ack, from); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case id := <-joined:
+		if id != 7 {
+			t.Fatalf("joined as player %d, want 7", id)
+		}
+	case <-time.After(waitFor):
+		t.Fatal("the client did not take the ack")
+	}
+}
@@ -7,70 +7,184 @@ import (
 	"snake-game/internal/game/ui"
 	prt "snake-game/internal/proto/gen"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const (
-	multicastAddr = "239.255.255.250:9999"
+	DefaultMulticastGroup   = "239.255.255.250:9999"
+	DefaultMulticastGroupV6 = "[ff02::efff:fffa]:9999"
+	defaultInterface        = "Беспроводная сеть"
 )
 
+// Manager is this node's side of the protocol. mu guards the state of the
+// game being played: role, masterAddr, gameAnnounce, the player table,
+// sessions and the rest. Handlers, timers and exported methods hold it; the
+// helpers they call expect it held and never take it themselves.
 type Manager struct {
 	unicastConn     *net.UDPConn
 	multicastConn   *net.UDPConn
+	multicastGroup  string
+	multicastIface  string
+	listenAddr      string
+	groupAddr       *net.UDPAddr
 	role            prt.NodeRole
-	msgSeq          int64
+	msgSeq          atomic.Int64
+	pending         *PendingQueue
+	lastStateOrder  int32
+	roleListener    interfaces.RoleListener
 	gameAnnounce    *prt.GameAnnouncement
 	ui              *ui.ConsoleUI
 	announceTicker  *time.Ticker
-	gameListener    interfaces.GameAnnouncementListener
+	browseTicker    *time.Ticker
 	stateListener   interfaces.GameStateListener
 	joinListener    interfaces.GameJoinListener
 	steerListener   interfaces.SteerListener
-	AvailableGames  map[string]*GameInfo
+	games           *GameRegistry
+	masterAddr      *net.UDPAddr
 	playerID        int32
 	mu              sync.Mutex
 	closeChan       chan struct{}
+	closeOnce       sync.Once
+	gameDone        chan struct{}
 	wg              sync.WaitGroup
-	JoinNotify      chan int32
 	activityManager *ActivityManager
+	errorListener   interfaces.GameErrorListener
+	banned          map[string]struct{}
+	sessions        map[string]*session
+	zones           map[string]string
+	extPeers        map[string]struct{}
+	noWrap          atomic.Bool
+	sessionStore    *SessionStore
+	join            *joinRequest
+	joinTokens      map[string]string
+	joinRole        prt.NodeRole
+	joining         bool
+	joinSeq         int64
+	joinNotify      chan int32
+	ended           bool
+	limiter         *rateLimiter
+	metrics         Metrics
 }
 
-type GameInfo struct {
-	Announcement *prt.GameAnnouncement
-	MasterAddr   *net.UDPAddr
+func NewNetworkManager(role prt.NodeRole, gameAnnounce *prt.GameAnnouncement) *Manager {
+	m := &Manager{
+		role:           role,
+		pending:        NewPendingQueue(),
+		gameAnnounce:   gameAnnounce,
+		ui:             ui.NewConsoleUI(),
+		games:          NewGameRegistry(),
+		multicastGroup: DefaultMulticastGroup,
+		multicastIface: defaultInterface,
+		listenAddr:     ":0",
+		closeChan:      make(chan struct{}),
+		gameDone:       make(chan struct{}),
+		banned:         make(map[string]struct{}),
+		sessions:       make(map[string]*session),
+		zones:          make(map[string]string),
+		extPeers:       make(map[string]struct{}),
+		joinTokens:     make(map[string]string),
+		limiter:        newRateLimiter(),
+		lastStateOrder: -1,
+	}
+	m.msgSeq.Store(1)
+	return m
 }
 
-func NewNetworkManager(role prt.NodeRole, gameAnnounce *prt.GameAnnouncement) *Manager {
-	return &Manager{
-		role:         role,
-		msgSeq:       1,
-		gameAnnounce: gameAnnounce,
-		ui:           ui.NewConsoleUI(),
-		closeChan:    make(chan struct{}),
+func (m *Manager) SetMulticastGroup(group string) {
+	switch group {
+	case "":
+		m.multicastGroup = DefaultMulticastGroup
+	case "v6", "ipv6":
+		m.multicastGroup = DefaultMulticastGroupV6
+	default:
+		m.multicastGroup = group
+	}
+}
+
+// SetListenAddr fixes the address of the unicast socket, e.g. ":9192", so
+// players can connect to this node directly. The default lets the system
+// pick a port.
+func (m *Manager) SetListenAddr(addr string) {
+	if addr == "" {
+		addr = ":0"
 	}
+	m.listenAddr = addr
+}
+
+func (m *Manager) SetSessionStore(store *SessionStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessionStore = store
+}
+
+func (m *Manager) SetMulticastInterface(name string) {
+	m.multicastIface = name
 }
 
 func (m *Manager) SetActivityManager(stateDelayMs int32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.activityManager != nil {
+		m.activityManager.Close()
+	}
 	m.activityManager = NewActivityManager(stateDelayMs, m)
+	if m.role == prt.NodeRole_MASTER {
+		m.monitorPlayers()
+	} else if m.masterAddr != nil {
+		m.activityManager.AddNodeToMonitor(m.masterAddr)
+	}
 }
 
-func (m *Manager) SetGameAnnouncementListener(listener interfaces.GameAnnouncementListener) {
-	m.gameListener = listener
+func (m *Manager) SetGameBrowserListener(listener interfaces.GameBrowserListener) {
+	m.games.SetListener(listener)
 }
 
 func (m *Manager) SetGameStateListener(listener interfaces.GameStateListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.stateListener = listener
 }
 
+// SetGameJoinListener hands the game to the manager. If listener is also a
+// sync.Locker, that lock guards the game: the manager takes it before mu
+// whenever something arrives from the network, and the listener's methods
+// are then called with it held. The owner of the game holds it in turn
+// while it calls methods that touch the player table, such as SendState.
 func (m *Manager) SetGameJoinListener(listener interfaces.GameJoinListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.joinListener = listener
 }
 
 func (m *Manager) SetSteerListener(listener interfaces.SteerListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.steerListener = listener
 }
 
+// lock takes the game lock, if there is one, and then mu, for work that
+// starts on a goroutine of the manager's own. Call the returned function to
+// release both.
+func (m *Manager) lock() func() {
+	m.mu.Lock()
+	game, _ := m.joinListener.(sync.Locker)
+	m.mu.Unlock()
+	if game != nil {
+		game.Lock()
+	}
+	m.mu.Lock()
+	return func() {
+		m.mu.Unlock()
+		if game != nil {
+			game.Unlock()
+		}
+	}
+}
+
 func (m *Manager) GetRole() prt.NodeRole {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.role
 }
 
@@ -84,6 +198,9 @@ func (m *Manager) Start() error {
 	m.wg.Add(2)
 	go m.listenForMessages()
 	go m.listenForMulticast()
+	m.startBrowsing()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.role == prt.NodeRole_MASTER {
 		m.startAnnouncementBroadcast()
 	}
@@ -91,7 +208,7 @@ func (m *Manager) Start() error {
 }
 
 func (m *Manager) setupUnicastSocket() error {
-	addr, err := net.ResolveUDPAddr("udp", ":0")
+	addr, err := net.ResolveUDPAddr("udp", m.listenAddr)
 	if err != nil {
 		return err
 	}
@@ -104,51 +221,68 @@ func (m *Manager) setupUnicastSocket() error {
 }
 
 func (m *Manager) startAnnouncementBroadcast() {
-	m.announceTicker = time.NewTicker(1 * time.Second)
+	if m.announceTicker != nil {
+		return
+	}
+	ticker := time.NewTicker(announceInterval)
+	m.announceTicker = ticker
+	gameDone := m.gameDone
 	go func() {
-		for range m.announceTicker.C {
-			m.sendAnnouncement()
+		for {
+			select {
+			case <-ticker.C:
+				unlock := m.lock()
+				m.sendAnnouncement()
+				unlock()
+			case <-gameDone:
+				return
+			case <-m.closeChan:
+				return
+			}
 		}
 	}()
 }
 
 func (m *Manager) setupMulticastSocket() error {
-	groupAddr, err := net.ResolveUDPAddr("udp", multicastAddr)
+	groupAddr, err := net.ResolveUDPAddr("udp", m.multicastGroup)
 	if err != nil {
 		return err
 	}
-	iface, err := net.InterfaceByName("Беспроводная сеть")
-	if err != nil {
-		return err
+	var iface *net.Interface
+	if m.multicastIface != "" {
+		iface, err = net.InterfaceByName(m.multicastIface)
+		if err != nil {
+			log.Printf("Multicast interface %q not found, using system default: %v", m.multicastIface, err)
+			iface = nil
+		}
 	}
-	conn, err := net.ListenMulticastUDP("udp", iface, groupAddr)
+	network := udpNetwork(groupAddr.IP)
+	conn, err := net.ListenMulticastUDP(network, iface, groupAddr)
 	if err != nil {
 		return err
 	}
+	if network == "udp6" && iface != nil && groupAddr.IP.IsLinkLocalMulticast() {
+		groupAddr.Zone = iface.Name
+	}
 	m.multicastConn = conn
+	m.groupAddr = groupAddr
 	return nil
 }
 
 func (m *Manager) listenForMessages() {
 	defer m.wg.Done()
-	buf := make([]byte, 4096)
-	for {
-		select {
-		case <-m.closeChan:
-			return
-		default:
-		}
-		n, addr, err := m.unicastConn.ReadFromUDP(buf)
-		if err != nil {
-			log.Printf("Error reading from UDP: %v", err)
-			continue
-		}
-		go m.handleMessage(buf[:n], addr)
-	}
+	m.readLoop(m.unicastConn, "UDP", m.handleMessage)
 }
 
 func (m *Manager) listenForMulticast() {
 	defer m.wg.Done()
+	m.readLoop(m.multicastConn, "multicast UDP", m.handleMulticastMessage)
+}
+
+// readLoop hands the datagrams on conn to handle one at a time, in the
+// order they arrive. Each one gets a copy of the bytes, so nothing handle
+// keeps can change under it when the buffer is reused.
+func (m *Manager) readLoop(conn *net.UDPConn, name string, handle func([]byte, *net.UDPAddr)) {
 	buf := make([]byte, 4096)
 	for {
 		select {
@@ -156,38 +290,62 @@ func (m *Manager) listenForMulticast() {
 			return
 		default:
 		}
-		n, addr, err := m.multicastConn.ReadFromUDP(buf)
+		n, addr, err := conn.ReadFromUDP(buf)
 		if err != nil {
-			log.Printf("Error reading from multicast UDP: %v", err)
+			log.Printf("Error reading from %s: %v", name, err)
 			continue
 		}
-		go m.handleMessage(buf[:n], addr)
+		data := make([]byte, n)
+		copy(data, buf[:n])
+		handle(data, addr)
 	}
 }
 
-func (m *Manager) ChangeRole(player *prt.GamePlayer, role prt.NodeRole) {
-	m.role = role
-	m.playerID = player.Id
-	for _, p := range m.gameAnnounce.GetPlayers().GetPlayers() {
-		if p.Id == player.Id {
-			p.Role = role
+func (m *Manager) startBrowsing() {
+	m.browseTicker = time.NewTicker(announceInterval)
+	go func() {
+		for {
+			select {
+			case <-m.browseTicker.C:
+				m.games.Expire(time.Now())
+				for _, addr := range m.games.BrowseAddrs() {
+					m.sendDiscover(addr)
+				}
+			case <-m.closeChan:
+				return
+			}
 		}
-	}
-	if role == prt.NodeRole_MASTER {
-		m.startAnnouncementBroadcast()
-	} else if m.announceTicker != nil {
-		m.announceTicker.Stop()
-		m.announceTicker = nil
-	}
+	}()
 }
 
-func (m *Manager) Close() {
+// ChangeRole makes player, which becomes this node's own player, take role.
+func (m *Manager) ChangeRole(player *prt.GamePlayer, role prt.NodeRole) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.playerID = player.Id
+	if p := findPlayer(m.players(), player.Id); p != nil {
+		player = p
+	}
+	return m.setPlayerRole(player, role)
+}
+
+func (m *Manager) Close() {
+	m.closeOnce.Do(m.close)
+}
+
+func (m *Manager) close() {
 	close(m.closeChan)
+	m.mu.Lock()
 	if m.announceTicker != nil {
 		m.announceTicker.Stop()
 	}
+	if m.activityManager != nil {
+		m.activityManager.Close()
+	}
+	m.mu.Unlock()
+	if m.browseTicker != nil {
+		m.browseTicker.Stop()
+	}
 	if m.unicastConn != nil {
 		m.unicastConn.Close()
 	}
@@ -198,16 +356,58 @@ func (m *Manager) Close() {
 }
 
 func (m *Manager) GetID() int32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.playerID
 }
 
+func (m *Manager) AvailableGames() []*GameInfo {
+	return m.games.Games()
+}
+
+func (m *Manager) LookupGame(gameName string) (*GameInfo, bool) {
+	return m.games.Lookup(gameName)
+}
+
+// FindGames lists every master announcing a game called gameName.
+func (m *Manager) FindGames(gameName string) []*GameInfo {
+	return m.games.Find(gameName)
+}
+
 func (m *Manager) SetGameAnnouncement(gameAnnounce *prt.GameAnnouncement) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.gameAnnounce = gameAnnounce
 }
 
-func (m *Manager) Kill(player *prt.GamePlayer) {
-	if m.joinListener != nil {
-		logic := m.joinListener.GetLogic()
-		logic.KillPlayer(player.Id)
+// gameConfig is the config of the game we play in. The first state may
+// arrive before the lobby has set the announcement after our join was
+// acked; it is checked against the game we asked to join.
+func (m *Manager) gameConfig() *prt.GameConfig {
+	if cfg := m.gameAnnounce.GetConfig(); cfg != nil {
+		return cfg
+	}
+	return m.join.GetGame().GetConfig()
+}
+
+// disconnect zombifies the snake of a player who stopped answering, so they
+// can reclaim it if they rejoin within the grace period.
+func (m *Manager) disconnect(player *prt.GamePlayer) {
+	if lgc := m.gameLogic(); lgc != nil {
+		lgc.DisconnectPlayer(player.Id)
+	}
+	m.markDisconnected(player.Id)
+}
+
+func (m *Manager) kill(player *prt.GamePlayer) {
+	if lgc := m.gameLogic(); lgc != nil {
+		lgc.KillPlayer(player.Id)
+	}
+}
+
+func (m *Manager) LocalAddr() *net.UDPAddr {
+	if m.unicastConn == nil {
+		return nil
 	}
+	return m.unicastConn.LocalAddr().(*net.UDPAddr)
 }
@@ -0,0 +1,42 @@
+package network
+
+import "sync/atomic"
+
+type Metrics struct {
+	Received          atomic.Int64
+	Invalid           atomic.Int64
+	RateLimited       atomic.Int64
+	SpoofedSteers     atomic.Int64
+	SpoofedRoleChange atomic.Int64
+	JoinsLimited      atomic.Int64
+	SteersLimited     atomic.Int64
+	DiscoversLimited  atomic.Int64
+}
+
+type MetricsSnapshot struct {
+	Received          int64 `json:"received"`
+	Invalid           int64 `json:"invalid"`
+	RateLimited       int64 `json:"rate_limited"`
+	SpoofedSteers     int64 `json:"spoofed_steers"`
+	SpoofedRoleChange int64 `json:"spoofed_role_changes"`
+	JoinsLimited      int64 `json:"joins_limited"`
+	SteersLimited     int64 `json:"steers_limited"`
+	DiscoversLimited  int64 `json:"discovers_limited"`
+}
+
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	return MetricsSnapshot{
+		Received:          m.Received.Load(),
+		Invalid:           m.Invalid.Load(),
+		RateLimited:       m.RateLimited.Load(),
+		SpoofedSteers:     m.SpoofedSteers.Load(),
+		SpoofedRoleChange: m.SpoofedRoleChange.Load(),
+		JoinsLimited:      m.JoinsLimited.Load(),
+		SteersLimited:     m.SteersLimited.Load(),
+		DiscoversLimited:  m.DiscoversLimited.Load(),
+	}
+}
+
+func (m *Manager) Metrics() MetricsSnapshot {
+	return m.metrics.Snapshot()
+}
@@ -0,0 +1,54 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"net"
+	prt "snake-game/internal/proto/gen"
+)
+
+const FieldFullMessage = "Cannot find suitable position for new snake"
+
+func (m *Manager) playerAt(addr *net.UDPAddr) *prt.GamePlayer {
+	for _, player := range m.players() {
+		if registered, err := playerAddr(player); err == nil && sameAddr(registered, addr) {
+			return player
+		}
+	}
+	return nil
+}
+
+// promotePlayer answers a JoinMsg from a node that is already in the game.
+// A VIEWER asking for NORMAL gets a snake if the field has room; anything
+// else is a repeated join and is simply acked again.
+func (m *Manager) promotePlayer(player *prt.GamePlayer, msg *prt.GameMessage, addr *net.UDPAddr) {
+	if player.GetRole() == prt.NodeRole_VIEWER && msg.GetJoin().GetRequestedRole() == prt.NodeRole_NORMAL {
+		if !m.gameAnnounce.GetCanJoin() {
+			m.sendError(addr, NotJoinableMessage)
+			return
+		}
+		if lgc := m.gameLogic(); lgc == nil || !lgc.SpawnSnake(player.GetId()) {
+			m.sendError(addr, FieldFullMessage)
+			return
+		}
+		m.setPlayerRole(player, prt.NodeRole_NORMAL)
+		log.Printf("Viewer %s (%d) started playing", player.GetName(), player.GetId())
+	}
+	if err := m.sendAck(msg.GetMsgSeq(), player.GetId(), addr); err != nil {
+		log.Printf("Error acking join from %s: %v", addr, err)
+	}
+	m.ensureDeputy()
+}
+
+// RequestPlay asks the master for a snake while watching as a VIEWER.
+func (m *Manager) RequestPlay() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.role != prt.NodeRole_VIEWER {
+		return fmt.Errorf("already playing as %s", m.role)
+	}
+	if m.masterAddr == nil || m.join == nil {
+		return fmt.Errorf("not in a game")
+	}
+	return m.sendJoin(prt.NodeRole_NORMAL, m.masterAddr)
+}
@@ -0,0 +1,106 @@
+package network
+
+import (
+	"net"
+	prt "snake-game/internal/proto/gen"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type limitKind int
+
+const (
+	limitJoin limitKind = iota
+	limitSteer
+	limitDiscover
+)
+
+type limit struct {
+	perSecond float64
+	burst     float64
+}
+
+var limits = map[limitKind]limit{
+	limitJoin:     {perSecond: 2, burst: 5},
+	limitSteer:    {perSecond: 50, burst: 100},
+	limitDiscover: {perSecond: 5, burst: 10},
+}
+
+const (
+	bucketIdleTimeout = time.Minute
+	pruneEvery        = 1024
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type bucketKey struct {
+	kind limitKind
+	addr string
+}
+
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[bucketKey]*bucket
+	calls   int
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[bucketKey]*bucket)}
+}
+
+func (rl *rateLimiter) allow(kind limitKind, addr *net.UDPAddr) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := time.Now()
+	rl.calls++
+	if rl.calls%pruneEvery == 0 {
+		rl.prune(now)
+	}
+	l := limits[kind]
+	key := bucketKey{kind: kind, addr: normalizeAddr(addr)}
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		rl.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.perSecond)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (rl *rateLimiter) prune(now time.Time) {
+	for key, b := range rl.buckets {
+		if now.Sub(b.last) > bucketIdleTimeout {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+func (m *Manager) allow(msg *prt.GameMessage, addr *net.UDPAddr) bool {
+	var kind limitKind
+	var counter *atomic.Int64
+	switch {
+	case msg.GetJoin() != nil:
+		kind, counter = limitJoin, &m.metrics.JoinsLimited
+	case msg.GetSteer() != nil:
+		kind, counter = limitSteer, &m.metrics.SteersLimited
+	case msg.GetDiscover() != nil:
+		kind, counter = limitDiscover, &m.metrics.DiscoversLimited
+	default:
+		return true
+	}
+	if m.limiter.allow(kind, addr) {
+		return true
+	}
+	m.metrics.RateLimited.Add(1)
+	counter.Add(1)
+	return false
+}
@@ -0,0 +1,238 @@
+package network
+
+import (
+	"net"
+	"snake-game/internal/game/interfaces"
+	prt "snake-game/internal/proto/gen"
+	"sync"
+	"time"
+)
+
+const (
+	announceInterval    = 1 * time.Second
+	missedAnnouncements = 3
+)
+
+type GameInfo struct {
+	Announcement *prt.GameAnnouncement
+	MasterAddr   *net.UDPAddr
+	LastSeen     time.Time
+	Latency      time.Duration
+}
+
+// directAddr is an address ConnectTo was pointed at. It stays on the browse
+// list for as long as a game there keeps answering.
+type directAddr struct {
+	addr     *net.UDPAddr
+	lastSeen time.Time
+}
+
+// GameRegistry lists the games seen on the network. Two masters may announce
+// games with the same name, so each game is known by its name together with
+// its master's address.
+type GameRegistry struct {
+	mu           sync.RWMutex
+	games        map[string]*GameInfo
+	discoverSent map[string]time.Time
+	direct       map[string]*directAddr
+	ttl          time.Duration
+	listener     interfaces.GameBrowserListener
+}
+
+func NewGameRegistry() *GameRegistry {
+	return &GameRegistry{
+		games:        make(map[string]*GameInfo),
+		discoverSent: make(map[string]time.Time),
+		direct:       make(map[string]*directAddr),
+		ttl:          missedAnnouncements * announceInterval,
+	}
+}
+
+func gameKey(gameName string, addr *net.UDPAddr) string {
+	return gameName + "@" + normalizeAddr(addr)
+}
+
+func (r *GameRegistry) SetListener(listener interfaces.GameBrowserListener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listener = listener
+}
+
+func (r *GameRegistry) Observe(games []*prt.GameAnnouncement, addr *net.UDPAddr, viaUnicast bool) {
+	now := time.Now()
+	r.mu.Lock()
+	var latency time.Duration
+	measured := false
+	if sent, ok := r.discoverSent[normalizeAddr(addr)]; ok && viaUnicast {
+		latency = now.Sub(sent)
+		measured = true
+		delete(r.discoverSent, normalizeAddr(addr))
+	}
+	if direct, ok := r.direct[normalizeAddr(addr)]; ok && len(games) > 0 {
+		direct.lastSeen = now
+	}
+	added := make([]*GameInfo, 0)
+	updated := make([]*GameInfo, 0)
+	for _, game := range games {
+		key := gameKey(game.GetGameName(), addr)
+		info, exists := r.games[key]
+		if !exists {
+			info = &GameInfo{}
+			r.games[key] = info
+		}
+		info.Announcement = game
+		info.MasterAddr = addr
+		info.LastSeen = now
+		if measured {
+			info.Latency = latency
+		}
+		snapshot := *info
+		if exists {
+			updated = append(updated, &snapshot)
+		} else {
+			added = append(added, &snapshot)
+		}
+	}
+	listener := r.listener
+	r.mu.Unlock()
+	if listener == nil {
+		return
+	}
+	for _, info := range added {
+		listener.OnGameAdded(info.Announcement, info.MasterAddr, info.Latency)
+	}
+	for _, info := range updated {
+		listener.OnGameUpdated(info.Announcement, info.MasterAddr, info.Latency)
+	}
+}
+
+func (r *GameRegistry) Expire(now time.Time) {
+	r.mu.Lock()
+	removed := make([]*GameInfo, 0)
+	for key, info := range r.games {
+		if now.Sub(info.LastSeen) > r.ttl {
+			delete(r.games, key)
+			removed = append(removed, info)
+		}
+	}
+	for addr, sent := range r.discoverSent {
+		if now.Sub(sent) > r.ttl {
+			delete(r.discoverSent, addr)
+		}
+	}
+	for addr, direct := range r.direct {
+		if now.Sub(direct.lastSeen) > r.ttl {
+			delete(r.direct, addr)
+		}
+	}
+	listener := r.listener
+	r.mu.Unlock()
+	if listener == nil {
+		return
+	}
+	for _, info := range removed {
+		listener.OnGameRemoved(info.Announcement.GetGameName(), info.MasterAddr)
+	}
+}
+
+func (r *GameRegistry) MarkDiscoverSent(addr *net.UDPAddr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, pending := r.discoverSent[normalizeAddr(addr)]; !pending {
+		r.discoverSent[normalizeAddr(addr)] = time.Now()
+	}
+}
+
+// AddDirect puts addr on the browse list, or keeps it there, as if a game
+// had just answered from it. Expire drops it once nothing has for a while.
+func (r *GameRegistry) AddDirect(addr *net.UDPAddr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.direct[normalizeAddr(addr)] = &directAddr{addr: addr, lastSeen: time.Now()}
+}
+
+// BrowseAddrs lists the masters to ask for fresh announcements: every master
+// already listed, plus the direct addresses that have not expired.
+func (r *GameRegistry) BrowseAddrs() []*net.UDPAddr {
+	addrs := r.MasterAddrs()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, direct := range r.direct {
+		listed := false
+		for _, addr := range addrs {
+			if sameAddr(addr, direct.addr) {
+				listed = true
+				break
+			}
+		}
+		if !listed {
+			addrs = append(addrs, direct.addr)
+		}
+	}
+	return addrs
+}
+
+// Lookup returns the game called gameName. If several masters announce that
+// name, the one heard from most recently wins; Find lists them all.
+func (r *GameRegistry) Lookup(gameName string) (*GameInfo, bool) {
+	var found *GameInfo
+	for _, info := range r.Find(gameName) {
+		if found == nil || info.LastSeen.After(found.LastSeen) {
+			found = info
+		}
+	}
+	return found, found != nil
+}
+
+func (r *GameRegistry) Find(gameName string) []*GameInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	games := make([]*GameInfo, 0)
+	for _, info := range r.games {
+		if info.Announcement.GetGameName() == gameName {
+			snapshot := *info
+			games = append(games, &snapshot)
+		}
+	}
+	return games
+}
+
+func (r *GameRegistry) Games() []*GameInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	games := make([]*GameInfo, 0, len(r.games))
+	for _, info := range r.games {
+		snapshot := *info
+		games = append(games, &snapshot)
+	}
+	return games
+}
+
+// GamesAt lists the games announced by the master at addr.
+func (r *GameRegistry) GamesAt(addr *net.UDPAddr) []*GameInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	games := make([]*GameInfo, 0)
+	for _, info := range r.games {
+		if sameAddr(info.MasterAddr, addr) {
+			snapshot := *info
+			games = append(games, &snapshot)
+		}
+	}
+	return games
+}
+
+func (r *GameRegistry) MasterAddrs() []*net.UDPAddr {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	seen := make(map[string]struct{})
+	addrs := make([]*net.UDPAddr, 0, len(r.games))
+	for _, info := range r.games {
+		if _, ok := seen[normalizeAddr(info.MasterAddr)]; ok {
+			continue
+		}
+		seen[normalizeAddr(info.MasterAddr)] = struct{}{}
+		addrs = append(addrs, info.MasterAddr)
+	}
+	return addrs
+}
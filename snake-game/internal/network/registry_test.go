@@ -0,0 +1,33 @@
+package network
+
+import (
+	"net"
+	prt "snake-game/internal/proto/gen"
+	"testing"
+	"time"
+)
+
+func TestDirectAddrExpires(t *testing.T) {
+	r := NewGameRegistry()
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9192}
+	r.AddDirect(addr)
+	if got := r.BrowseAddrs(); len(got) != 1 || !sameAddr(got[0], addr) {
+		t.Fatalf("browse list is %v, want %s", got, addr)
+	}
+
+	r.Expire(time.Now().Add(r.ttl / 2))
+	if got := r.BrowseAddrs(); len(got) != 1 {
+		t.Fatalf("direct address dropped before its ttl: %v", got)
+	}
+
+	r.Observe([]*prt.GameAnnouncement{{GameName: "direct"}}, addr, true)
+	r.Expire(time.Now().Add(r.ttl / 2))
+	if got := r.BrowseAddrs(); len(got) != 1 {
+		t.Fatalf("answering direct address dropped: %v", got)
+	}
+
+	r.Expire(time.Now().Add(2 * r.ttl))
+	if got := r.BrowseAddrs(); len(got) != 0 {
+		t.Fatalf("silent direct address is still browsed: %v", got)
+	}
+}
@@ -0,0 +1,168 @@
+package network
+
+import (
+	"google.golang.org/protobuf/proto"
+	"log"
+	"net"
+	prt "snake-game/internal/proto/gen"
+	"sync"
+	"time"
+)
+
+// pendingMsg is a sent message still waiting for its AckMsg. Messages for
+// the master follow it when a deputy takes over.
+type pendingMsg struct {
+	data     []byte
+	addr     *net.UDPAddr
+	sentAt   time.Time
+	toMaster bool
+	kind     string
+}
+
+// PendingQueue holds sent messages until they are acked and resends them
+// when they are due. The relay keeps what it owes its viewers in one too.
+type PendingQueue struct {
+	mu   sync.Mutex
+	msgs map[int64]*pendingMsg
+}
+
+func NewPendingQueue() *PendingQueue {
+	return &PendingQueue{msgs: make(map[int64]*pendingMsg)}
+}
+
+// Add keeps data, the marshaled msg just sent to addr, until addr acks it.
+// A newer state or ping replaces an unacked older one to the same node.
+func (q *PendingQueue) Add(msg *prt.GameMessage, data []byte, addr *net.UDPAddr) {
+	q.add(msg, data, addr, false)
+}
+
+func (q *PendingQueue) add(msg *prt.GameMessage, data []byte, addr *net.UDPAddr, toMaster bool) {
+	p := &pendingMsg{data: data, addr: addr, sentAt: time.Now(), toMaster: toMaster, kind: supersededKind(msg)}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if p.kind != "" {
+		for seq, old := range q.msgs {
+			if old.kind == p.kind && sameAddr(old.addr, addr) {
+				delete(q.msgs, seq)
+			}
+		}
+	}
+	q.msgs[msg.GetMsgSeq()] = p
+}
+
+// Ack drops the pending message an AckMsg from addr answers and reports
+// whether there was one.
+func (q *PendingQueue) Ack(seq int64, addr *net.UDPAddr) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	p, ok := q.msgs[seq]
+	if !ok || !sameAddr(p.addr, addr) {
+		return false
+	}
+	delete(q.msgs, seq)
+	return true
+}
+
+// Retransmit hands every message unacked for interval to send again.
+func (q *PendingQueue) Retransmit(interval time.Duration, send func(data []byte, addr *net.UDPAddr)) {
+	now := time.Now()
+	q.mu.Lock()
+	due := make([]*pendingMsg, 0)
+	for _, p := range q.msgs {
+		if now.Sub(p.sentAt) >= interval {
+			p.sentAt = now
+			due = append(due, p)
+		}
+	}
+	q.mu.Unlock()
+	for _, p := range due {
+		send(p.data, p.addr)
+	}
+}
+
+// Drop forgets everything owed to addr, or everything when addr is nil.
+func (q *PendingQueue) Drop(addr *net.UDPAddr) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for seq, p := range q.msgs {
+		if addr == nil || sameAddr(p.addr, addr) {
+			delete(q.msgs, seq)
+		}
+	}
+}
+
+func (m *Manager) nextSeq() int64 {
+	return m.msgSeq.Add(1) - 1
+}
+
+// NextSeq and SetNextSeq let a resumed game keep numbering its messages
+// where it left off.
+func (m *Manager) NextSeq() int64 {
+	return m.msgSeq.Load()
+}
+
+func (m *Manager) SetNextSeq(seq int64) {
+	if seq > m.msgSeq.Load() {
+		m.msgSeq.Store(seq)
+	}
+}
+
+// sendReliable stamps msg with a fresh msg_seq, sends it and keeps resending
+// it every state_delay_ms/10 until the receiver acks it.
+func (m *Manager) sendReliable(msg *prt.GameMessage, addr *net.UDPAddr, toMaster bool) error {
+	msg.MsgSeq = m.nextSeq()
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	m.pending.add(msg, data, addr, toMaster)
+	return m.send(data, addr)
+}
+
+func (m *Manager) retransmit(interval time.Duration) {
+	m.pending.Retransmit(interval, func(data []byte, addr *net.UDPAddr) {
+		if err := m.send(data, addr); err != nil {
+			log.Printf("Error retransmitting to %s: %v", addr, err)
+		}
+	})
+}
+
+// retargetPending points everything still owed to the old master at the new
+// one, or drops it when this node has become the master itself.
+func (m *Manager) retargetPending(newMaster *net.UDPAddr) {
+	m.pending.mu.Lock()
+	defer m.pending.mu.Unlock()
+	for seq, p := range m.pending.msgs {
+		if !p.toMaster {
+			continue
+		}
+		if newMaster == nil {
+			delete(m.pending.msgs, seq)
+			continue
+		}
+		p.addr = newMaster
+		p.sentAt = time.Time{}
+	}
+}
+
+// supersededKind names the messages where only the latest one to a node
+// matters: a newer state or ping makes any unacked older one pointless.
+func supersededKind(msg *prt.GameMessage) string {
+	switch {
+	case msg.GetState() != nil:
+		return "state"
+	case msg.GetPing() != nil:
+		return "ping"
+	}
+	return ""
+}
+
+// needsAck reports whether the protocol wants an AckMsg for msg. Joins are
+// acked by handleJoin itself, since the ack carries the new player's id.
+func needsAck(msg *prt.GameMessage) bool {
+	switch {
+	case msg.GetAnnouncement() != nil, msg.GetDiscover() != nil, msg.GetAck() != nil, msg.GetJoin() != nil:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package network
+
+import (
+	"log"
+	"slices"
+	"snake-game/internal/game/interfaces"
+	"snake-game/internal/game/logic"
+	prt "snake-game/internal/proto/gen"
+)
+
+// legalRoleChanges is the role state machine. A NORMAL player only becomes
+// MASTER by way of DEPUTY, a master handing the game over may stay on as
+// the deputy, and nobody leaves VIEWER except to play again.
+var legalRoleChanges = map[prt.NodeRole][]prt.NodeRole{
+	prt.NodeRole_NORMAL: {prt.NodeRole_DEPUTY, prt.NodeRole_VIEWER},
+	prt.NodeRole_DEPUTY: {prt.NodeRole_MASTER, prt.NodeRole_NORMAL, prt.NodeRole_VIEWER},
+	prt.NodeRole_MASTER: {prt.NodeRole_DEPUTY, prt.NodeRole_VIEWER},
+	prt.NodeRole_VIEWER: {prt.NodeRole_NORMAL},
+}
+
+func canChangeRole(from, to prt.NodeRole) bool {
+	return from == to || slices.Contains(legalRoleChanges[from], to)
+}
+
+func (m *Manager) SetRoleListener(listener interfaces.RoleListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roleListener = listener
+}
+
+// players is the authoritative player table, GameState.players of the game
+// being played. The announcement shares it on the master.
+func (m *Manager) players() []*prt.GamePlayer {
+	if lgc := m.gameLogic(); lgc != nil {
+		return lgc.GetPlayers().GetPlayers()
+	}
+	return m.gameAnnounce.GetPlayers().GetPlayers()
+}
+
+// gameLogic is the game this node runs, or nil while it has none.
+func (m *Manager) gameLogic() *logic.GameLogic {
+	if m.joinListener == nil {
+		return nil
+	}
+	return m.joinListener.GetLogic()
+}
+
+func (m *Manager) deputy() *prt.GamePlayer {
+	for _, p := range m.players() {
+		if p.Role == prt.NodeRole_DEPUTY {
+			return p
+		}
+	}
+	return nil
+}
+
+// setPlayerRole moves player along the state machine and refuses anything
+// it does not allow.
+func (m *Manager) setPlayerRole(player *prt.GamePlayer, role prt.NodeRole) bool {
+	if !canChangeRole(player.Role, role) {
+		log.Printf("Refused role change for player %d: %v -> %v", player.GetId(), player.Role, role)
+		return false
+	}
+	player.Role = role
+	if player.Id == m.playerID {
+		m.setOwnRole(role)
+	}
+	return true
+}
+
+func (m *Manager) setOwnRole(role prt.NodeRole) {
+	changed := m.role != role
+	m.role = role
+	if role == prt.NodeRole_MASTER {
+		m.startAnnouncementBroadcast()
+		m.monitorPlayers()
+	} else if m.announceTicker != nil {
+		m.announceTicker.Stop()
+		m.announceTicker = nil
+	}
+	if changed && m.roleListener != nil {
+		m.roleListener.OnRoleChanged(role)
+	}
+}
+
+// ensureDeputy elects a deputy when the master has none, picking the first
+// NORMAL player we can reach.
+func (m *Manager) ensureDeputy() {
+	if m.role != prt.NodeRole_MASTER || m.deputy() != nil {
+		return
+	}
+	for _, p := range m.players() {
+		if p.Id == m.playerID || p.Role != prt.NodeRole_NORMAL {
+			continue
+		}
+		if _, err := playerAddr(p); err != nil {
+			continue
+		}
+		if m.setPlayerRole(p, prt.NodeRole_DEPUTY) {
+			m.sendRoleChangeMessage(p, prt.NodeRole_DEPUTY)
+			log.Printf("Player %s (%d) is the new deputy", p.GetName(), p.GetId())
+		}
+		return
+	}
+}
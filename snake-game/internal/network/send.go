@@ -5,32 +5,56 @@ import (
 	"google.golang.org/protobuf/proto"
 	"log"
 	"net"
+	"snake-game/internal/game/logic"
 	prt "snake-game/internal/proto/gen"
-	"strconv"
+	"time"
 )
 
-func (m *Manager) SendUnicastMessage(data []byte, addr *net.UDPAddr) error {
+func (m *Manager) send(data []byte, addr *net.UDPAddr) error {
 	if m.activityManager != nil {
 		m.activityManager.RecordMessageSent(addr)
 	}
-	_, err := m.unicastConn.WriteToUDP(data, addr)
+	_, err := m.unicastConn.WriteToUDP(data, m.withZone(addr))
 	return err
 }
 
-func (m *Manager) SendJoinRequest(playerType prt.PlayerType, playerName string, gameName string, role prt.NodeRole) error {
-	gameInfo, exists := m.AvailableGames[gameName]
-	if !exists {
-		return fmt.Errorf("game %s not found", gameName)
+func (m *Manager) sendError(addr *net.UDPAddr, text string) {
+	message := &prt.GameMessage{
+		SenderId: m.playerID,
+		Type:     &prt.GameMessage_Error{Error: &prt.GameMessage_ErrorMsg{ErrorMessage: text}},
+	}
+	if err := m.sendReliable(message, addr, false); err != nil {
+		log.Printf("Error sending error message: %v", err)
+	}
+}
+
+// SendJoinRequest asks the master of game, as found in AvailableGames or
+// LookupGame, to let us in. The returned channel receives our player ID
+// once the master takes us.
+func (m *Manager) SendJoinRequest(playerType prt.PlayerType, playerName string, game *GameInfo, role prt.NodeRole) (<-chan int32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.masterAddr = game.MasterAddr
+	m.join = &joinRequest{game: game.Announcement, playerName: playerName, playerType: playerType}
+	joined := make(chan int32, 1)
+	m.joinNotify = joined
+	if err := m.sendJoin(role, game.MasterAddr); err != nil {
+		m.joinNotify = nil
+		return nil, err
 	}
+	return joined, nil
+}
 
+func (m *Manager) sendJoin(role prt.NodeRole, addr *net.UDPAddr) error {
+	gameName := m.join.GetGame().GetGameName()
 	joinMsg := &prt.GameMessage_JoinMsg{
-		PlayerType:    playerType,
-		PlayerName:    playerName,
+		PlayerType:    m.join.playerType,
+		PlayerName:    m.join.playerName,
 		GameName:      gameName,
 		RequestedRole: role,
 	}
 	msg := &prt.GameMessage{
-		MsgSeq: m.msgSeq,
+		MsgSeq: m.nextSeq(),
 		Type: &prt.GameMessage_Join{
 			Join: joinMsg,
 		},
@@ -40,129 +64,168 @@ func (m *Manager) SendJoinRequest(playerType prt.PlayerType, playerName string,
 		return fmt.Errorf("marshaling join message: %v", err)
 	}
 
-	err = m.SendUnicastMessage(data, gameInfo.MasterAddr)
+	m.joining = true
+	m.joinSeq = msg.MsgSeq
+	m.joinRole = role
+	hello := &extension{Hello: true}
+	if m.sessionStore != nil {
+		hello.Token = m.sessionStore.Token(gameName, m.join.playerName)
+	}
+	m.sendExtension(hello, addr)
+	err = m.send(data, addr)
 	if err != nil {
 		return fmt.Errorf("sending join request: %v", err)
 	}
+	go m.resendJoin(msg.MsgSeq, data, addr, joinResendInterval(m.join.GetGame().GetConfig()))
 
-	m.msgSeq++
 	log.Printf("Join request sent to %s for game: %s, role: %v",
-		gameInfo.MasterAddr, gameName, role)
+		addr, gameName, role)
 	return nil
 }
 
-func (m *Manager) sendAnnouncement() {
-	if m.role != prt.NodeRole_MASTER {
-		return
+// resendJoin repeats the JoinMsg numbered seq until the master acks it with
+// our player ID, or we give up on the game. Other messages are resent by the
+// activity manager, but that only starts once we are in.
+func (m *Manager) resendJoin(seq int64, data []byte, addr *net.UDPAddr, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.closeChan:
+			return
+		}
+		m.mu.Lock()
+		if !m.joining || m.joinSeq != seq {
+			m.mu.Unlock()
+			return
+		}
+		if err := m.send(data, addr); err != nil {
+			log.Printf("Error resending join request to %s: %v", addr, err)
+		}
+		m.mu.Unlock()
 	}
+}
+
+// joinResendInterval is state_delay_ms/10 of the game being joined, as for
+// any other message. Announcements are validated, so the floor only matters
+// for a GameInfo made up by hand.
+func joinResendInterval(cfg *prt.GameConfig) time.Duration {
+	delayMs := max(cfg.GetStateDelayMs(), minStateDelayMs)
+	return time.Duration(delayMs) * time.Millisecond / 10
+}
+
+func (m *Manager) announcementData() ([]byte, error) {
 	announcementMsg := &prt.GameMessage_AnnouncementMsg{
 		Games: []*prt.GameAnnouncement{m.gameAnnounce},
 	}
 	msg := &prt.GameMessage{
-		MsgSeq: m.msgSeq,
+		MsgSeq: m.nextSeq(),
 		Type: &prt.GameMessage_Announcement{
 			Announcement: announcementMsg,
 		},
 	}
-	data, err := proto.Marshal(msg)
+	return proto.Marshal(msg)
+}
+
+func (m *Manager) sendAnnouncement() {
+	if m.role != prt.NodeRole_MASTER {
+		return
+	}
+	data, err := m.announcementData()
 	if err != nil {
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
 
-	groupAddr, _ := net.ResolveUDPAddr("udp", multicastAddr)
-	_, err = m.multicastConn.WriteToUDP(data, groupAddr)
+	_, err = m.unicastConn.WriteToUDP(data, m.groupAddr)
 	if err != nil {
 		log.Printf("Error sending announcement: %v", err)
-		return
 	}
-	m.msgSeq++
 }
 
-func (m *Manager) SendState(gameState *prt.GameState) error {
-	stateMsg := &prt.GameMessage_StateMsg{State: gameState}
+func (m *Manager) sendDiscover(addr *net.UDPAddr) {
 	msg := &prt.GameMessage{
-		MsgSeq: m.msgSeq,
-		Type:   &prt.GameMessage_State{State: stateMsg},
+		MsgSeq: m.nextSeq(),
+		Type:   &prt.GameMessage_Discover{Discover: &prt.GameMessage_DiscoverMsg{}},
 	}
 	data, err := proto.Marshal(msg)
 	if err != nil {
-		return fmt.Errorf("error marshaling state message: %v", err)
+		log.Printf("Error marshaling discover message: %v", err)
+		return
+	}
+	m.games.MarkDiscoverSent(addr)
+	if _, err = m.unicastConn.WriteToUDP(data, addr); err != nil {
+		log.Printf("Error sending discover to %s: %v", addr, err)
 	}
+}
 
-	for _, player := range m.gameAnnounce.GetPlayers().GetPlayers() {
+// SendState sends gameState to every other player, walls included: they are
+// ZOMBIE snakes without a player, which any client draws and dies on. The
+// caller holds the game lock, if the game has one, since the player table
+// is read.
+func (m *Manager) SendState(gameState *prt.GameState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cfg := m.gameAnnounce.GetConfig()
+	if cfg == nil {
+		return fmt.Errorf("no game to send state for")
+	}
+	encoded := logic.EncodeState(gameState, cfg.GetWidth(), cfg.GetHeight())
+	for _, player := range m.players() {
 		if player.GetId() == m.playerID {
 			continue
 		}
-		port := strconv.Itoa(int(player.GetPort()))
-		playerAddr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(player.IpAddress, port))
+		addr, err := playerAddr(player)
 		if err != nil {
 			log.Printf("Error resolving player address: %v", err)
 			continue
 		}
-
-		err = m.SendUnicastMessage(data, playerAddr)
-		if err != nil {
+		msg := &prt.GameMessage{
+			SenderId:   m.playerID,
+			ReceiverId: player.GetId(),
+			Type:       &prt.GameMessage_State{State: &prt.GameMessage_StateMsg{State: encoded}},
+		}
+		if err := m.sendReliable(msg, addr, false); err != nil {
 			log.Printf("Error sending state to player %d: %v", player.GetId(), err)
 		}
 	}
-
-	m.msgSeq++
 	return nil
 }
 
 func (m *Manager) SendSteer(dir prt.Direction) error {
-	steerMsg := &prt.GameMessage_SteerMsg{Direction: dir}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.masterAddr == nil {
+		return fmt.Errorf("master address not known")
+	}
 	msg := &prt.GameMessage{
-		MsgSeq:   m.msgSeq,
-		Type:     &prt.GameMessage_Steer{Steer: steerMsg},
+		Type:     &prt.GameMessage_Steer{Steer: &prt.GameMessage_SteerMsg{Direction: dir}},
 		SenderId: m.playerID,
 	}
-	data, err := proto.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("error marshaling steer message: %v", err)
-	}
-
-	gameInfo, exists := m.AvailableGames[m.gameAnnounce.GameName]
-	if !exists {
-		return fmt.Errorf("game info not found")
-	}
-
-	err = m.SendUnicastMessage(data, gameInfo.MasterAddr)
-	if err != nil {
+	if err := m.sendReliable(msg, m.masterAddr, true); err != nil {
 		return fmt.Errorf("error sending steer: %v", err)
 	}
-
-	m.msgSeq++
 	return nil
 }
 
 func (m *Manager) sendPing(addr *net.UDPAddr) error {
 	pingMsg := &prt.GameMessage_PingMsg{}
 	msg := &prt.GameMessage{
-		MsgSeq: m.msgSeq,
-		Type:   &prt.GameMessage_Ping{Ping: pingMsg},
-	}
-
-	data, err := proto.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("marshaling ping message: %v", err)
+		SenderId: m.playerID,
+		Type:     &prt.GameMessage_Ping{Ping: pingMsg},
 	}
-
-	err = m.SendUnicastMessage(data, addr)
-	if err != nil {
+	if err := m.sendReliable(msg, addr, m.fromMaster(addr)); err != nil {
 		return fmt.Errorf("sending ping: %v", err)
 	}
-
-	m.msgSeq++
-	//log.Printf("Sent PING to %s", addr)
 	return nil
 }
 
-func (m *Manager) SendAck(msgSeq int64, receiverId int32, addr *net.UDPAddr) error {
+func (m *Manager) sendAck(msgSeq int64, receiverId int32, addr *net.UDPAddr) error {
 	ackMsg := &prt.GameMessage_AckMsg{}
 	msg := &prt.GameMessage{
 		MsgSeq:     msgSeq,
+		SenderId:   m.playerID,
 		ReceiverId: receiverId,
 		Type:       &prt.GameMessage_Ack{Ack: ackMsg},
 	}
@@ -172,83 +235,46 @@ func (m *Manager) SendAck(msgSeq int64, receiverId int32, addr *net.UDPAddr) err
 		return fmt.Errorf("marshaling ack message: %v", err)
 	}
 
-	err = m.SendUnicastMessage(data, addr)
+	err = m.send(data, addr)
 	if err != nil {
 		return fmt.Errorf("sending ack: %v", err)
 	}
-
-	log.Printf("Sent ACK for message seq %d to %s", msgSeq, addr)
 	return nil
 }
 
 func (m *Manager) sendRoleChangeMessage(player *prt.GamePlayer, newRole prt.NodeRole) {
-	roleChangeMsg := &prt.GameMessage_RoleChangeMsg{
-		SenderRole:   m.role,
-		ReceiverRole: newRole,
-	}
+	m.sendRoleChange(player, m.role, newRole)
+}
 
+func (m *Manager) sendRoleChange(player *prt.GamePlayer, senderRole, receiverRole prt.NodeRole) {
 	msg := &prt.GameMessage{
-		MsgSeq:     m.msgSeq,
 		SenderId:   m.playerID,
 		ReceiverId: player.GetId(),
-		Type:       &prt.GameMessage_RoleChange{RoleChange: roleChangeMsg},
-	}
-
-	data, err := proto.Marshal(msg)
-	if err != nil {
-		log.Printf("Error marshaling role change message: %v", err)
-		return
+		Type: &prt.GameMessage_RoleChange{RoleChange: &prt.GameMessage_RoleChangeMsg{
+			SenderRole:   senderRole,
+			ReceiverRole: receiverRole,
+		}},
 	}
-
-	playerAddr, err := net.ResolveUDPAddr("udp",
-		net.JoinHostPort(player.GetIpAddress(), strconv.Itoa(int(player.GetPort()))))
+	addr, err := playerAddr(player)
 	if err != nil {
 		log.Printf("Error resolving player address: %v", err)
 		return
 	}
-
-	err = m.SendUnicastMessage(data, playerAddr)
-	if err != nil {
+	if err := m.sendReliable(msg, addr, false); err != nil {
 		log.Printf("Error sending role change message: %v", err)
 		return
 	}
-
-	log.Printf("Sent role change message to player %s, new role: %v", player.GetName(), newRole)
-	m.msgSeq++
+	log.Printf("Sent role change to player %s: %v/%v", player.GetName(), senderRole, receiverRole)
 }
 
+// broadcastNewMaster tells every other player, viewers included, where the
+// master now is.
 func (m *Manager) broadcastNewMaster() {
-	for _, player := range m.gameAnnounce.GetPlayers().GetPlayers() {
-		if player.Role == prt.NodeRole_VIEWER || player.Id == m.playerID {
-			continue
-		}
-		roleChangeMsg := &prt.GameMessage_RoleChangeMsg{
-			SenderRole:   prt.NodeRole_MASTER,
-			ReceiverRole: player.Role,
-		}
-		msg := &prt.GameMessage{
-			MsgSeq:     m.msgSeq,
-			SenderId:   m.playerID,
-			ReceiverId: player.Id,
-			Type:       &prt.GameMessage_RoleChange{RoleChange: roleChangeMsg},
-		}
-		data, err := proto.Marshal(msg)
-		if err != nil {
-			log.Printf("Error marshaling new master announcement: %v", err)
-			continue
-		}
-		playerAddr, err := net.ResolveUDPAddr("udp",
-			net.JoinHostPort(player.IpAddress, strconv.Itoa(int(player.Port))))
-		if err != nil {
-			log.Printf("Error resolving player address: %v", err)
-			continue
-		}
-		err = m.SendUnicastMessage(data, playerAddr)
-		if err != nil {
-			log.Printf("Error sending new master announcement: %v", err)
+	for _, player := range m.players() {
+		if player.Id == m.playerID {
 			continue
 		}
-		m.msgSeq++
+		m.sendRoleChange(player, prt.NodeRole_MASTER, player.Role)
 	}
 	log.Printf("Broadcasted new master announcement to all players")
 }
@@ -0,0 +1,217 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"snake-game/internal/game/config"
+	prt "snake-game/internal/proto/gen"
+	"sync"
+	"time"
+)
+
+// Rejoin tokens travel in extensions: the master hands one out after the
+// join ack, and the client sends it back in the hello that precedes its
+// next JoinMsg for the same game and name. Other implementations never see
+// them.
+const (
+	RejoinGrace = 30 * time.Second
+	// ResumeGrace is how long players of a resumed saved game have to return.
+	ResumeGrace     = 5 * time.Minute
+	sessionsFile    = "sessions.json"
+	savedSessionTTL = 24 * time.Hour
+)
+
+type session struct {
+	token          string
+	playerID       int32
+	name           string
+	disconnectedAt time.Time
+	grace          time.Duration
+}
+
+func newSessionToken() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// joinRequest is the game we last asked to join and who we asked as. It
+// is needed again to ask for a snake while watching and to file the token
+// the master hands out.
+type joinRequest struct {
+	game       *prt.GameAnnouncement
+	playerName string
+	playerType prt.PlayerType
+}
+
+func (r *joinRequest) GetGame() *prt.GameAnnouncement {
+	if r == nil {
+		return nil
+	}
+	return r.game
+}
+
+// sendSession hands a rejoin token to a client that speaks extensions.
+func (m *Manager) sendSession(token string, addr *net.UDPAddr) {
+	if m.speaksExtension(addr) {
+		m.sendExtension(&extension{NoWrap: m.gameNoWrap(), Token: token}, addr)
+	}
+}
+
+// saveSession files a token from the master under the game and name we
+// joined with.
+func (m *Manager) saveSession(token string) {
+	if m.sessionStore == nil || m.join == nil {
+		return
+	}
+	if err := m.sessionStore.Save(m.join.game.GetGameName(), m.join.playerName, token); err != nil {
+		log.Printf("Failed to save session: %v", err)
+	}
+}
+
+// takeJoinToken returns the token the hello from addr carried, once.
+func (m *Manager) takeJoinToken(addr *net.UDPAddr) string {
+	key := normalizeAddr(addr)
+	token := m.joinTokens[key]
+	delete(m.joinTokens, key)
+	return token
+}
+
+func (m *Manager) issueSession(playerID int32, name string) string {
+	token := newSessionToken()
+	m.sessions[token] = &session{token: token, playerID: playerID, name: name}
+	return token
+}
+
+func (m *Manager) markDisconnected(playerID int32) {
+	for _, s := range m.sessions {
+		if s.playerID == playerID {
+			s.disconnectedAt = time.Now()
+		}
+	}
+}
+
+func (m *Manager) dropSessions(playerID int32) {
+	for token, s := range m.sessions {
+		if s.playerID == playerID {
+			delete(m.sessions, token)
+		}
+	}
+}
+
+// claimSession returns the session for a rejoining player, dropping it if
+// the grace period is over.
+func (m *Manager) claimSession(name, token string) *session {
+	s, ok := m.sessions[token]
+	if !ok || s.name != name {
+		return nil
+	}
+	grace := RejoinGrace
+	if s.grace > 0 {
+		grace = s.grace
+	}
+	if !s.disconnectedAt.IsZero() && time.Since(s.disconnectedAt) > grace {
+		delete(m.sessions, token)
+		return nil
+	}
+	s.disconnectedAt = time.Time{}
+	s.grace = 0
+	return s
+}
+
+// SessionRecord is a rejoin token as kept in a saved game.
+type SessionRecord struct {
+	Token    string `json:"token"`
+	PlayerID int32  `json:"player_id"`
+	Name     string `json:"name"`
+}
+
+func (m *Manager) Sessions() []SessionRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	records := make([]SessionRecord, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		records = append(records, SessionRecord{Token: s.token, PlayerID: s.playerID, Name: s.name})
+	}
+	return records
+}
+
+// RestoreSessions hands the tokens of a saved game out again. Their players
+// count as disconnected from now on and get ResumeGrace to come back.
+func (m *Manager) RestoreSessions(records []SessionRecord) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, r := range records {
+		m.sessions[r.Token] = &session{token: r.Token, playerID: r.PlayerID, name: r.Name, disconnectedAt: now, grace: ResumeGrace}
+	}
+}
+
+type SavedSession struct {
+	Game   string    `json:"game"`
+	Player string    `json:"player"`
+	Token  string    `json:"token"`
+	Saved  time.Time `json:"saved"`
+}
+
+type SessionStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func DefaultSessionStore() (*SessionStore, error) {
+	dir, err := config.UserDir()
+	if err != nil {
+		return nil, fmt.Errorf("locating config directory: %v", err)
+	}
+	return &SessionStore{path: filepath.Join(dir, sessionsFile)}, nil
+}
+
+func (s *SessionStore) load() map[string]*SavedSession {
+	sessions := make(map[string]*SavedSession)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return sessions
+	}
+	json.Unmarshal(data, &sessions)
+	for key, saved := range sessions {
+		if time.Since(saved.Saved) > savedSessionTTL {
+			delete(sessions, key)
+		}
+	}
+	return sessions
+}
+
+func sessionKey(game, player string) string {
+	return game + "/" + player
+}
+
+func (s *SessionStore) Token(game, player string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if saved, ok := s.load()[sessionKey(game, player)]; ok {
+		return saved.Token
+	}
+	return ""
+}
+
+func (s *SessionStore) Save(game, player, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := s.load()
+	sessions[sessionKey(game, player)] = &SavedSession{Game: game, Player: player, Token: token, Saved: time.Now()}
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling sessions: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("writing sessions: %v", err)
+	}
+	return nil
+}
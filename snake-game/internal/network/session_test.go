@@ -0,0 +1,93 @@
+package network_test
+
+import (
+	"fmt"
+	"snake-game/internal/conformance"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"testing"
+	"time"
+)
+
+// TestRejoinReclaimsSnake drops a player and brings them back from another
+// address with the token the master handed out over the extension channel.
+func TestRejoinReclaimsSnake(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	store, err := network.DefaultSessionStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, master := hostGame(t, "127.0.0.1:0")
+	addr := fmt.Sprintf("127.0.0.1:%d", master.LocalAddr().Port)
+
+	first := newManager(t, "127.0.0.1:0")
+	first.SetSessionStore(store)
+	id := join(t, first, addr, "player", prt.NodeRole_NORMAL)
+	eventually(t, "the client gets a token", func() bool { return store.Token("loopback", "player") != "" })
+	first.Close()
+	eventually(t, "the master notices the player is gone", func() bool {
+		player := findPlayer(host.Players(), id)
+		return player != nil && player.GetRole() == prt.NodeRole_VIEWER
+	})
+
+	second := newManager(t, "127.0.0.1:0")
+	second.SetSessionStore(store)
+	states := &lastState{}
+	second.SetGameStateListener(states)
+	if rejoined := join(t, second, addr, "player", prt.NodeRole_NORMAL); rejoined != id {
+		t.Fatalf("rejoined as player %d, want %d", rejoined, id)
+	}
+	eventually(t, "the old snake is alive again", func() bool {
+		for _, snake := range states.get().GetSnakes() {
+			if snake.GetPlayerId() == id {
+				return snake.GetState() == prt.GameState_Snake_ALIVE
+			}
+		}
+		return false
+	})
+}
+
+// TestForeignJoinSeesNoToken checks that a client that only speaks the
+// protocol gets a plain ack and no error carrying a token.
+func TestForeignJoinSeesNoToken(t *testing.T) {
+	_, master := hostGame(t, "127.0.0.1:0")
+	peer, err := conformance.NewPeer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+	peer.Send(&prt.GameMessage{Type: &prt.GameMessage_Join{Join: &prt.GameMessage_JoinMsg{
+		PlayerName: "foreign", GameName: "loopback", RequestedRole: prt.NodeRole_NORMAL,
+	}}}, master.LocalAddr())
+	received := peer.Collect(3*testDelayMs*time.Millisecond, func(r *conformance.Received) bool { return r.Msg.GetError() != nil })
+	for _, r := range received {
+		t.Errorf("foreign client got error %q", r.Msg.GetError().GetErrorMessage())
+	}
+}
+
+// TestViewerWithTokenStartsPlaying checks that a viewer of our own client,
+// which sends its token with every join, gets a fresh snake when it asks to
+// play instead of going down the rejoin path.
+func TestViewerWithTokenStartsPlaying(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	store, err := network.DefaultSessionStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, master := hostGame(t, "127.0.0.1:0")
+	client := newManager(t, "127.0.0.1:0")
+	client.SetSessionStore(store)
+	id := join(t, client, fmt.Sprintf("127.0.0.1:%d", master.LocalAddr().Port), "viewer", prt.NodeRole_VIEWER)
+	eventually(t, "the client gets a token", func() bool { return store.Token("loopback", "viewer") != "" })
+
+	if err := client.RequestPlay(); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the master lets the viewer play", func() bool {
+		player := findPlayer(host.Players(), id)
+		return player != nil && player.GetRole() != prt.NodeRole_VIEWER
+	})
+	eventually(t, "the client plays", func() bool { return client.GetRole() != prt.NodeRole_VIEWER })
+}
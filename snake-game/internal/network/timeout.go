@@ -4,16 +4,29 @@ import (
 	"log"
 	"net"
 	prt "snake-game/internal/proto/gen"
-	"strconv"
 )
 
 func (m *Manager) handleNodeTimeout(addr *net.UDPAddr) {
+	unlock := m.lock()
+	defer unlock()
+	if m.ended {
+		return
+	}
 	log.Printf("Node %s timed out", addr)
+	if m.role == prt.NodeRole_MASTER {
+		m.pending.Drop(addr)
+	}
+	if m.role != prt.NodeRole_MASTER && m.fromMaster(addr) {
+		m.handleMasterLost()
+		return
+	}
 	var timedOutPlayer *prt.GamePlayer
-	for _, player := range m.gameAnnounce.GetPlayers().GetPlayers() {
-		playerAddr := net.JoinHostPort(player.GetIpAddress(), strconv.Itoa(int(player.GetPort())))
-		expectedAddr := net.JoinHostPort(addr.IP.String(), strconv.Itoa(addr.Port))
-		if playerAddr == expectedAddr {
+	for _, player := range m.players() {
+		pAddr, err := playerAddr(player)
+		if err != nil {
+			continue
+		}
+		if sameAddr(pAddr, addr) {
 			timedOutPlayer = player
 			break
 		}
@@ -26,65 +39,73 @@ func (m *Manager) handleNodeTimeout(addr *net.UDPAddr) {
 		m.handleMasterTimeout(timedOutPlayer)
 	case prt.NodeRole_DEPUTY:
 		m.handleDeputyTimeout(timedOutPlayer)
-	case prt.NodeRole_NORMAL:
-		m.handleNormalTimeout(timedOutPlayer)
 	}
 }
 
+// handleMasterTimeout turns a vanished player into a VIEWER with a zombie
+// snake and, if it was the deputy, elects another one.
 func (m *Manager) handleMasterTimeout(player *prt.GamePlayer) {
-	if player.Role == prt.NodeRole_DEPUTY {
-		var newDeputy *prt.GamePlayer
-		for _, p := range m.gameAnnounce.GetPlayers().GetPlayers() {
-			if p.Id != player.Id && p.Role == prt.NodeRole_NORMAL {
-				newDeputy = p
-				break
-			}
-		}
-		if newDeputy != nil {
-			m.sendRoleChangeMessage(newDeputy, prt.NodeRole_DEPUTY)
-		}
-	}
-	m.sendRoleChangeMessage(player, prt.NodeRole_VIEWER)
-	m.Kill(player)
+	m.setPlayerRole(player, prt.NodeRole_VIEWER)
+	m.disconnect(player)
+	m.ensureDeputy()
 }
 
 func (m *Manager) handleDeputyTimeout(player *prt.GamePlayer) {
 	if player.Role == prt.NodeRole_MASTER {
-		m.ChangeRole(player, prt.NodeRole_MASTER)
-		var newDeputy *prt.GamePlayer
-		for _, p := range m.gameAnnounce.GetPlayers().GetPlayers() {
-			if p.Role == prt.NodeRole_NORMAL {
-				newDeputy = p
-				break
-			}
-		}
-		if newDeputy != nil {
-			m.sendRoleChangeMessage(newDeputy, prt.NodeRole_DEPUTY)
-		}
-		m.broadcastNewMaster()
+		m.takeOver(player)
 	}
 }
 
-func (m *Manager) handleNormalTimeout(player *prt.GamePlayer) {
-	if player.Role == prt.NodeRole_MASTER {
-		var deputy *prt.GamePlayer
-		for _, p := range m.gameAnnounce.GetPlayers().GetPlayers() {
-			if p.Role == prt.NodeRole_DEPUTY {
-				deputy = p
-				break
-			}
+// takeOver makes this deputy the master after the old one disappeared.
+func (m *Manager) takeOver(oldMaster *prt.GamePlayer) {
+	ourPlayer := findPlayer(m.players(), m.playerID)
+	if ourPlayer == nil {
+		return
+	}
+	if !canChangeRole(ourPlayer.Role, prt.NodeRole_MASTER) {
+		log.Printf("Cannot take over as %s", ourPlayer.Role)
+		return
+	}
+	if oldMaster != nil && oldMaster.Id != m.playerID {
+		m.setPlayerRole(oldMaster, prt.NodeRole_VIEWER)
+		m.disconnect(oldMaster)
+	}
+	m.masterAddr = nil
+	m.retargetPending(nil)
+	m.setPlayerRole(ourPlayer, prt.NodeRole_MASTER)
+	m.ensureDeputy()
+	m.broadcastNewMaster()
+}
+
+// handleMasterLost runs on every non-master node when the master stops
+// answering. The deputy takes over, everyone else switches to the deputy,
+// and without one the game is over.
+func (m *Manager) handleMasterLost() {
+	var oldMaster *prt.GamePlayer
+	for _, p := range m.players() {
+		if p.Role == prt.NodeRole_MASTER {
+			oldMaster = p
 		}
-		if deputy != nil {
-			for _, gameInfo := range m.AvailableGames {
-				if gameInfo.Announcement.GameName == m.gameAnnounce.GameName {
-					deputyAddr, err := net.ResolveUDPAddr("udp",
-						net.JoinHostPort(deputy.IpAddress, strconv.Itoa(int(deputy.Port))))
-					if err == nil {
-						gameInfo.MasterAddr = deputyAddr
-					}
-					break
-				}
+	}
+	if m.role == prt.NodeRole_DEPUTY {
+		m.takeOver(oldMaster)
+		return
+	}
+	if deputy := m.deputy(); deputy != nil && deputy.Id != m.playerID {
+		if deputyAddr, err := playerAddr(deputy); err == nil {
+			m.masterAddr = deputyAddr
+			m.retargetPending(deputyAddr)
+			if m.activityManager != nil {
+				m.activityManager.AddNodeToMonitor(deputyAddr)
 			}
+			m.sendHello(deputyAddr)
+			log.Printf("Master lost, switching to deputy %s", deputy.GetName())
+			return
 		}
 	}
+	m.ended = true
+	log.Printf("Master lost and no deputy to take over")
+	if m.errorListener != nil {
+		m.errorListener.OnErrorReceived(MasterLostMessage)
+	}
 }
@@ -0,0 +1,192 @@
+package network
+
+import (
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"snake-game/internal/game/logic"
+	prt "snake-game/internal/proto/gen"
+)
+
+const (
+	minFieldSide     = 10
+	maxFieldSide     = 100
+	maxFoodStatic    = 100
+	minStateDelayMs  = 100
+	maxStateDelayMs  = 3000
+	maxNameLength    = 64
+	maxErrorLength   = 512
+	maxAnnouncements = 16
+)
+
+func decodeMessage(data []byte) (*prt.GameMessage, error) {
+	var msg prt.GameMessage
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("unmarshaling: %v", err)
+	}
+	if msg.GetType() == nil {
+		return nil, fmt.Errorf("message has no type")
+	}
+	if msg.GetMsgSeq() < 0 {
+		return nil, fmt.Errorf("negative msg_seq %d", msg.GetMsgSeq())
+	}
+	return &msg, nil
+}
+
+func validateMessage(msg *prt.GameMessage, cfg *prt.GameConfig, players []*prt.GamePlayer) error {
+	switch {
+	case msg.GetSteer() != nil:
+		if !validDirection(msg.GetSteer().GetDirection()) {
+			return fmt.Errorf("steer has unknown direction %d", msg.GetSteer().GetDirection())
+		}
+		if findPlayer(players, msg.GetSenderId()) == nil {
+			return fmt.Errorf("steer from unknown sender %d", msg.GetSenderId())
+		}
+	case msg.GetState() != nil:
+		if cfg == nil {
+			return fmt.Errorf("state received while not in a game")
+		}
+		return validateState(msg.GetState().GetState(), cfg)
+	case msg.GetAnnouncement() != nil:
+		games := msg.GetAnnouncement().GetGames()
+		if len(games) == 0 || len(games) > maxAnnouncements {
+			return fmt.Errorf("announcement has %d games", len(games))
+		}
+		for _, game := range games {
+			if err := validateAnnouncement(game); err != nil {
+				return err
+			}
+		}
+	case msg.GetJoin() != nil:
+		return validateJoin(msg.GetJoin())
+	case msg.GetError() != nil:
+		if len(msg.GetError().GetErrorMessage()) > maxErrorLength {
+			return fmt.Errorf("error message too long")
+		}
+	case msg.GetRoleChange() != nil:
+		roleChange := msg.GetRoleChange()
+		if !validRole(roleChange.GetSenderRole()) || !validRole(roleChange.GetReceiverRole()) {
+			return fmt.Errorf("role change has unknown role")
+		}
+	}
+	return nil
+}
+
+func validateState(state *prt.GameState, cfg *prt.GameConfig) error {
+	if state == nil {
+		return fmt.Errorf("state message without state")
+	}
+	if state.GetStateOrder() < 0 {
+		return fmt.Errorf("negative state_order %d", state.GetStateOrder())
+	}
+	if state.GetPlayers() == nil {
+		return fmt.Errorf("state without players")
+	}
+	seen := make(map[int32]struct{})
+	for _, player := range state.GetPlayers().GetPlayers() {
+		if player == nil {
+			return fmt.Errorf("state has empty player")
+		}
+		if _, dup := seen[player.GetId()]; dup {
+			return fmt.Errorf("state has duplicate player %d", player.GetId())
+		}
+		seen[player.GetId()] = struct{}{}
+		if !validRole(player.GetRole()) {
+			return fmt.Errorf("player %d has unknown role", player.GetId())
+		}
+	}
+	for _, snake := range state.GetSnakes() {
+		if snake == nil || len(snake.GetPoints()) == 0 {
+			return fmt.Errorf("state has snake without points")
+		}
+		if !validDirection(snake.GetHeadDirection()) {
+			return fmt.Errorf("snake %d has unknown direction", snake.GetPlayerId())
+		}
+		if _, ok := prt.GameState_Snake_SnakeState_name[int32(snake.GetState())]; !ok {
+			return fmt.Errorf("snake %d has unknown state", snake.GetPlayerId())
+		}
+		points := snake.GetPoints()
+		if !insideField(points[0], cfg) {
+			return fmt.Errorf("snake %d has its head outside the field", snake.GetPlayerId())
+		}
+		cells := 1
+		for _, offset := range points[1:] {
+			if offset == nil || !logic.IsKeyOffset(offset, cfg.GetWidth(), cfg.GetHeight()) {
+				return fmt.Errorf("snake %d has offset that is not along one axis", snake.GetPlayerId())
+			}
+			cells += logic.OffsetLength(offset)
+			if cells > int(cfg.GetWidth())*int(cfg.GetHeight()) {
+				return fmt.Errorf("snake %d is longer than the field", snake.GetPlayerId())
+			}
+		}
+	}
+	for _, food := range state.GetFoods() {
+		if !insideField(food, cfg) {
+			return fmt.Errorf("food outside the field")
+		}
+	}
+	return nil
+}
+
+func validateAnnouncement(game *prt.GameAnnouncement) error {
+	if game == nil || game.GetGameName() == "" || len(game.GetGameName()) > maxNameLength {
+		return fmt.Errorf("announcement has invalid game name")
+	}
+	if game.GetPlayers() == nil {
+		return fmt.Errorf("announcement %q has no players", game.GetGameName())
+	}
+	return validateConfig(game.GetConfig())
+}
+
+func validateConfig(cfg *prt.GameConfig) error {
+	switch {
+	case cfg == nil:
+		return fmt.Errorf("missing game config")
+	case cfg.GetWidth() < minFieldSide || cfg.GetWidth() > maxFieldSide:
+		return fmt.Errorf("width %d out of range", cfg.GetWidth())
+	case cfg.GetHeight() < minFieldSide || cfg.GetHeight() > maxFieldSide:
+		return fmt.Errorf("height %d out of range", cfg.GetHeight())
+	case cfg.GetFoodStatic() < 0 || cfg.GetFoodStatic() > maxFoodStatic:
+		return fmt.Errorf("food_static %d out of range", cfg.GetFoodStatic())
+	case cfg.GetStateDelayMs() < minStateDelayMs || cfg.GetStateDelayMs() > maxStateDelayMs:
+		return fmt.Errorf("state_delay_ms %d out of range", cfg.GetStateDelayMs())
+	}
+	return nil
+}
+
+func validateJoin(join *prt.GameMessage_JoinMsg) error {
+	name := join.GetPlayerName()
+	if name == "" || len(name) > maxNameLength {
+		return fmt.Errorf("join has invalid player name")
+	}
+	if _, ok := prt.PlayerType_name[int32(join.GetPlayerType())]; !ok {
+		return fmt.Errorf("join has unknown player type")
+	}
+	if join.GetRequestedRole() != prt.NodeRole_NORMAL && join.GetRequestedRole() != prt.NodeRole_VIEWER {
+		return fmt.Errorf("join requests role %s", join.GetRequestedRole())
+	}
+	return nil
+}
+
+func insideField(coord *prt.GameState_Coord, cfg *prt.GameConfig) bool {
+	return coord != nil && coord.GetX() >= 0 && coord.GetX() < cfg.GetWidth() &&
+		coord.GetY() >= 0 && coord.GetY() < cfg.GetHeight()
+}
+
+func validDirection(dir prt.Direction) bool {
+	_, ok := prt.Direction_name[int32(dir)]
+	return ok
+}
+
+func validRole(role prt.NodeRole) bool {
+	_, ok := prt.NodeRole_name[int32(role)]
+	return ok
+}
+
+func findPlayer(players []*prt.GamePlayer, id int32) *prt.GamePlayer {
+	for _, player := range players {
+		if player.GetId() == id {
+			return player
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package network
+
+import (
+	"github.com/golang/protobuf/proto"
+	"net"
+	"snake-game/internal/game/logic"
+	prt "snake-game/internal/proto/gen"
+	"testing"
+	"time"
+)
+
+func fuzzConfig() *prt.GameConfig {
+	return &prt.GameConfig{Width: 10, Height: 10, FoodStatic: 1, StateDelayMs: 200}
+}
+
+func seedState() *prt.GameState {
+	return &prt.GameState{
+		StateOrder: 3,
+		Players: &prt.GamePlayers{Players: []*prt.GamePlayer{
+			{Name: "a", Id: 1, Role: prt.NodeRole_MASTER},
+		}},
+		Foods: []*prt.GameState_Coord{{X: 4, Y: 4}},
+		Snakes: []*prt.GameState_Snake{{
+			PlayerId: 1,
+			// Crosses the left border: the tail is on the far right.
+			Points:        []*prt.GameState_Coord{{X: 0, Y: 2}, {X: 9, Y: 2}, {X: 8, Y: 2}, {X: 8, Y: 3}},
+			HeadDirection: prt.Direction_LEFT,
+		}},
+	}
+}
+
+func TestValidateState(t *testing.T) {
+	cfg := fuzzConfig()
+	encoded := logic.EncodeState(seedState(), cfg.GetWidth(), cfg.GetHeight())
+	if err := validateState(encoded, cfg); err != nil {
+		t.Fatalf("encoded state rejected: %v", err)
+	}
+	long := logic.EncodeState(seedState(), cfg.GetWidth(), cfg.GetHeight())
+	long.Snakes[0].Points[1] = &prt.GameState_Coord{X: -3}
+	if err := validateState(long, cfg); err != nil {
+		t.Fatalf("long offset rejected: %v", err)
+	}
+	tests := []struct {
+		name   string
+		mutate func(*prt.GameState_Snake)
+	}{
+		{"head outside", func(s *prt.GameState_Snake) { s.Points[0].X = 10 }},
+		{"zero offset", func(s *prt.GameState_Snake) { s.Points[1] = &prt.GameState_Coord{} }},
+		{"diagonal offset", func(s *prt.GameState_Snake) { s.Points[1] = &prt.GameState_Coord{X: 1, Y: 1} }},
+		{"offset around the field", func(s *prt.GameState_Snake) { s.Points[1] = &prt.GameState_Coord{X: -10} }},
+		{"longer than the field", func(s *prt.GameState_Snake) {
+			for range 12 {
+				s.Points = append(s.Points, &prt.GameState_Coord{X: 9}, &prt.GameState_Coord{Y: 1})
+			}
+		}},
+		{"absolute body", func(s *prt.GameState_Snake) { s.Points = seedState().Snakes[0].Points }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := logic.EncodeState(seedState(), cfg.GetWidth(), cfg.GetHeight())
+			tt.mutate(state.Snakes[0])
+			if err := validateState(state, cfg); err == nil {
+				t.Fatalf("state accepted: %v", state.Snakes[0].Points)
+			}
+		})
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	cfg := fuzzConfig()
+	// Runs once around the torus and on: no offset may span the whole field.
+	around := seedState()
+	around.Snakes[0].Points = nil
+	for x := int32(13); x >= 0; x-- {
+		around.Snakes[0].Points = append(around.Snakes[0].Points, &prt.GameState_Coord{X: x % 10, Y: 5})
+	}
+	for _, state := range []*prt.GameState{seedState(), around} {
+		encoded := logic.EncodeState(state, cfg.GetWidth(), cfg.GetHeight())
+		if err := validateState(encoded, cfg); err != nil {
+			t.Fatalf("encoded state rejected: %v (%v)", err, encoded.Snakes[0].Points)
+		}
+		decoded := logic.DecodeState(encoded, cfg.GetWidth(), cfg.GetHeight())
+		if !proto.Equal(decoded, state) {
+			t.Fatalf("round trip changed the state:\n got %v\nwant %v", decoded.Snakes[0].Points, state.Snakes[0].Points)
+		}
+	}
+}
+
+// FuzzValidateState checks that whatever passes validation decodes into a
+// snake that lies entirely inside the field.
+func FuzzValidateState(f *testing.F) {
+	cfg := fuzzConfig()
+	seed, _ := proto.Marshal(logic.EncodeState(seedState(), cfg.GetWidth(), cfg.GetHeight()))
+	f.Add(seed)
+	absolute, _ := proto.Marshal(seedState())
+	f.Add(absolute)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var state prt.GameState
+		if err := proto.Unmarshal(data, &state); err != nil {
+			return
+		}
+		if validateState(&state, cfg) != nil {
+			return
+		}
+		decoded := logic.DecodeState(&state, cfg.GetWidth(), cfg.GetHeight())
+		for _, snake := range decoded.GetSnakes() {
+			for _, point := range snake.GetPoints() {
+				if !insideField(point, cfg) {
+					t.Fatalf("valid state decodes to %v outside the field", point)
+				}
+			}
+		}
+	})
+}
+
+// FuzzDecodeMessage feeds arbitrary datagrams through the same checks the
+// unicast socket applies; none of them may panic.
+func FuzzDecodeMessage(f *testing.F) {
+	cfg := fuzzConfig()
+	players := seedState().GetPlayers().GetPlayers()
+	seeds := []*prt.GameMessage{
+		{MsgSeq: 1, Type: &prt.GameMessage_Ping{Ping: &prt.GameMessage_PingMsg{}}},
+		{MsgSeq: 2, SenderId: 1, Type: &prt.GameMessage_Steer{Steer: &prt.GameMessage_SteerMsg{Direction: prt.Direction_UP}}},
+		{MsgSeq: 3, Type: &prt.GameMessage_State{State: &prt.GameMessage_StateMsg{
+			State: logic.EncodeState(seedState(), cfg.GetWidth(), cfg.GetHeight()),
+		}}},
+		{MsgSeq: 4, Type: &prt.GameMessage_Join{Join: &prt.GameMessage_JoinMsg{
+			PlayerName: "a", GameName: "g", RequestedRole: prt.NodeRole_NORMAL,
+		}}},
+		{MsgSeq: 5, Type: &prt.GameMessage_Announcement{Announcement: &prt.GameMessage_AnnouncementMsg{
+			Games: []*prt.GameAnnouncement{{GameName: "g", Config: cfg, Players: &prt.GamePlayers{}}},
+		}}},
+		{MsgSeq: 6, Type: &prt.GameMessage_RoleChange{RoleChange: &prt.GameMessage_RoleChangeMsg{
+			SenderRole: prt.NodeRole_MASTER, ReceiverRole: prt.NodeRole_DEPUTY,
+		}}},
+	}
+	for _, msg := range seeds {
+		data, _ := proto.Marshal(msg)
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg, err := decodeMessage(data)
+		if err != nil {
+			return
+		}
+		if validateMessage(msg, cfg, players) != nil {
+			return
+		}
+		if state := msg.GetState().GetState(); state != nil {
+			logic.DecodeState(state, cfg.GetWidth(), cfg.GetHeight())
+		}
+	})
+}
+
+// fuzzGame is a join listener with whatever logic the fuzz mode gives it,
+// nil included.
+type fuzzGame struct {
+	logic *logic.GameLogic
+}
+
+func (g *fuzzGame) OnGameAddPlayer(player *prt.GamePlayer) {
+	g.logic.AddPlayer(player)
+}
+
+func (g *fuzzGame) GetLogic() *logic.GameLogic {
+	return g.logic
+}
+
+// fuzzManager sets up a node in one of a few situations, with only as much
+// of a game around it as the mode asks for. Replies go out on conn.
+func fuzzManager(mode byte, conn *net.UDPConn, sender *net.UDPAddr) *Manager {
+	cfg := fuzzConfig()
+	players := []*prt.GamePlayer{
+		{Name: "master", Id: 1, Role: prt.NodeRole_MASTER},
+		{Name: "peer", Id: 2, Role: prt.NodeRole_NORMAL, IpAddress: sender.IP.String(), Port: int32(sender.Port)},
+	}
+	m := NewNetworkManager(prt.NodeRole_MASTER, &prt.GameAnnouncement{
+		GameName: "fuzz", Config: cfg, CanJoin: true, Players: &prt.GamePlayers{Players: players},
+	})
+	m.unicastConn = conn
+	m.playerID = 1
+	partialActivity := &ActivityManager{
+		lastSent:     make(map[string]time.Time),
+		lastRecv:     make(map[string]time.Time),
+		stateDelayMs: cfg.GetStateDelayMs(),
+		manager:      m,
+		done:         make(chan struct{}),
+	}
+	switch mode % 5 {
+	case 0:
+		m.role = prt.NodeRole_NORMAL
+		m.playerID = 3
+		m.masterAddr = sender
+	case 1:
+		m.role = prt.NodeRole_DEPUTY
+		m.playerID = 3
+		m.masterAddr = sender
+	case 2:
+		// A master whose game is not set up yet.
+	case 3:
+		gl := logic.NewGameLogic(cfg)
+		for _, player := range players {
+			gl.AddPlayer(proto.Clone(player).(*prt.GamePlayer))
+		}
+		m.joinListener = &fuzzGame{logic: gl}
+		m.activityManager = partialActivity
+	case 4:
+		m.joinListener = &fuzzGame{}
+		m.activityManager = partialActivity
+	}
+	return m
+}
+
+// FuzzHandleMessage feeds two datagrams from one sender to the handlers of
+// a node set up by fuzzManager; none of them may panic.
+func FuzzHandleMessage(f *testing.F) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		f.Skipf("cannot open a socket: %v", err)
+	}
+	defer conn.Close()
+	sender := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9}
+	cfg := fuzzConfig()
+	seeds := []*prt.GameMessage{
+		{MsgSeq: 1, Type: &prt.GameMessage_Join{Join: &prt.GameMessage_JoinMsg{
+			PlayerName: "a", GameName: "fuzz", RequestedRole: prt.NodeRole_NORMAL,
+		}}},
+		{MsgSeq: 2, SenderId: 2, Type: &prt.GameMessage_Steer{Steer: &prt.GameMessage_SteerMsg{Direction: prt.Direction_UP}}},
+		{MsgSeq: 3, SenderId: 1, Type: &prt.GameMessage_State{State: &prt.GameMessage_StateMsg{
+			State: logic.EncodeState(seedState(), cfg.GetWidth(), cfg.GetHeight()),
+		}}},
+		{MsgSeq: 4, SenderId: 2, Type: &prt.GameMessage_RoleChange{RoleChange: &prt.GameMessage_RoleChangeMsg{
+			SenderRole: prt.NodeRole_VIEWER, ReceiverRole: prt.NodeRole_MASTER,
+		}}},
+		{MsgSeq: 5, SenderId: 1, ReceiverId: 3, Type: &prt.GameMessage_RoleChange{RoleChange: &prt.GameMessage_RoleChangeMsg{
+			SenderRole: prt.NodeRole_MASTER, ReceiverRole: prt.NodeRole_DEPUTY,
+		}}},
+		{MsgSeq: 6, SenderId: 1, Type: &prt.GameMessage_Error{Error: &prt.GameMessage_ErrorMsg{ErrorMessage: KickedMessage}}},
+		{MsgSeq: 7, SenderId: 1, ReceiverId: 3, Type: &prt.GameMessage_Ack{Ack: &prt.GameMessage_AckMsg{}}},
+		{MsgSeq: 8, Type: &prt.GameMessage_Discover{Discover: &prt.GameMessage_DiscoverMsg{}}},
+	}
+	for mode := range byte(5) {
+		for _, first := range seeds {
+			a, _ := proto.Marshal(first)
+			for _, second := range seeds {
+				b, _ := proto.Marshal(second)
+				f.Add(mode, a, b)
+			}
+		}
+	}
+	f.Fuzz(func(t *testing.T, mode byte, first, second []byte) {
+		m := fuzzManager(mode, conn, sender)
+		defer close(m.closeChan)
+		m.handleMessage(first, sender)
+		m.handleMessage(second, sender)
+	})
+}
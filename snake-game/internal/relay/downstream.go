@@ -0,0 +1,169 @@
+package relay
+
+import (
+	"cmp"
+	"google.golang.org/protobuf/proto"
+	"log"
+	"net"
+	"net/netip"
+	"slices"
+	"snake-game/internal/game/logic"
+	prt "snake-game/internal/proto/gen"
+	"time"
+)
+
+func addrKey(addr *net.UDPAddr) string {
+	ap := addr.AddrPort()
+	return netip.AddrPortFrom(ap.Addr().Unmap(), ap.Port()).String()
+}
+
+// handle answers one message from downstream. Everything stays between the
+// relay and its viewers: pings and acks are answered here, and steers are
+// acked so the viewer stops resending them, but go nowhere.
+func (r *Relay) handle(msg *prt.GameMessage, addr *net.UDPAddr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := addrKey(addr)
+	v := r.viewers[key]
+	if v != nil {
+		v.lastRecv = time.Now()
+	}
+	switch {
+	case msg.GetDiscover() != nil:
+		r.sendAnnouncement(addr)
+	case msg.GetJoin() != nil:
+		r.handleJoin(msg, addr, v)
+	case msg.GetAck() != nil:
+		r.pending.Ack(msg.GetMsgSeq(), addr)
+	case v == nil:
+		return
+	case msg.GetSteer() != nil:
+		r.steers.Add(1)
+		r.ack(msg, v)
+	case msg.GetRoleChange() != nil:
+		r.ack(msg, v)
+		if msg.GetRoleChange().GetSenderRole() == prt.NodeRole_VIEWER {
+			log.Printf("Viewer %s (%d) left", v.name, v.id)
+			r.removeViewer(key)
+		}
+	case msg.GetAnnouncement() == nil:
+		r.ack(msg, v)
+	}
+}
+
+func (r *Relay) handleJoin(msg *prt.GameMessage, addr *net.UDPAddr, v *viewer) {
+	join := msg.GetJoin()
+	if join.GetRequestedRole() != prt.NodeRole_VIEWER {
+		r.sendError(addr, ViewOnlyMessage)
+		return
+	}
+	if v == nil {
+		name := join.GetPlayerName()
+		if r.announce == nil || name == "" {
+			return
+		}
+		if len(r.viewers) >= r.maxViewers {
+			r.sendError(addr, RelayFullMessage)
+			return
+		}
+		now := time.Now()
+		v = &viewer{id: r.newViewerID(), name: name, addr: addr, lastSent: now, lastRecv: now}
+		r.viewers[addrKey(addr)] = v
+		log.Printf("Viewer %s (%d) joined from %s", v.name, v.id, addr)
+	}
+	r.ack(msg, v)
+}
+
+// newViewerID picks an id no player upstream and no other viewer has, so
+// a viewer never mistakes someone else's snake for its own.
+func (r *Relay) newViewerID() int32 {
+	taken := map[int32]struct{}{r.playerID: {}}
+	for _, player := range r.announce.GetPlayers().GetPlayers() {
+		taken[player.GetId()] = struct{}{}
+	}
+	for _, v := range r.viewers {
+		taken[v.id] = struct{}{}
+	}
+	for {
+		id := logic.GeneratePlayerID()
+		if _, exists := taken[id]; !exists && id != 0 {
+			return id
+		}
+	}
+}
+
+func (r *Relay) ack(msg *prt.GameMessage, v *viewer) {
+	r.sendWithSeq(&prt.GameMessage{
+		MsgSeq:     msg.GetMsgSeq(),
+		SenderId:   r.playerID,
+		ReceiverId: v.id,
+		Type:       &prt.GameMessage_Ack{Ack: &prt.GameMessage_AckMsg{}},
+	}, v.addr)
+}
+
+func (r *Relay) sendError(addr *net.UDPAddr, text string) {
+	r.send(&prt.GameMessage{
+		SenderId: r.playerID,
+		Type:     &prt.GameMessage_Error{Error: &prt.GameMessage_ErrorMsg{ErrorMessage: text}},
+	}, addr)
+}
+
+func (r *Relay) sendAnnouncement(addr *net.UDPAddr) {
+	if r.announce == nil {
+		return
+	}
+	announce := proto.Clone(r.announce).(*prt.GameAnnouncement)
+	announce.Players = r.players()
+	r.send(&prt.GameMessage{
+		Type: &prt.GameMessage_Announcement{Announcement: &prt.GameMessage_AnnouncementMsg{
+			Games: []*prt.GameAnnouncement{announce},
+		}},
+	}, addr)
+}
+
+// players lists the upstream players followed by the relay's own viewers,
+// which the master upstream never hears of.
+func (r *Relay) players() *prt.GamePlayers {
+	players := slices.Clone(r.announce.GetPlayers().GetPlayers())
+	viewers := make([]*prt.GamePlayer, 0, len(r.viewers))
+	for _, v := range r.viewers {
+		viewers = append(viewers, &prt.GamePlayer{Name: v.name, Id: v.id, Role: prt.NodeRole_VIEWER, Type: prt.PlayerType_HUMAN})
+	}
+	slices.SortFunc(viewers, func(a, b *prt.GamePlayer) int { return cmp.Compare(a.GetId(), b.GetId()) })
+	return &prt.GamePlayers{Players: append(players, viewers...)}
+}
+
+// send stamps msg with a fresh msg_seq and sends it once, for the
+// announcements and errors that need no ack.
+func (r *Relay) send(msg *prt.GameMessage, addr *net.UDPAddr) {
+	msg.MsgSeq = r.msgSeq.Add(1) - 1
+	r.sendWithSeq(msg, addr)
+}
+
+// sendReliable sends msg and lets monitor resend it until the viewer acks
+// it, as every other node does.
+func (r *Relay) sendReliable(msg *prt.GameMessage, addr *net.UDPAddr) {
+	msg.MsgSeq = r.msgSeq.Add(1) - 1
+	if data := r.sendWithSeq(msg, addr); data != nil {
+		r.pending.Add(msg, data, addr)
+	}
+}
+
+func (r *Relay) sendWithSeq(msg *prt.GameMessage, addr *net.UDPAddr) []byte {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		log.Printf("Error marshaling message for %s: %v", addr, err)
+		return nil
+	}
+	r.write(data, addr)
+	return data
+}
+
+func (r *Relay) write(data []byte, addr *net.UDPAddr) {
+	if v := r.viewers[addrKey(addr)]; v != nil {
+		v.lastSent = time.Now()
+	}
+	if _, err := r.conn.WriteToUDP(data, addr); err != nil {
+		log.Printf("Error sending to %s: %v", addr, err)
+	}
+}
@@ -0,0 +1,281 @@
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"log"
+	"net"
+	"snake-game/internal/game/logic"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultListenAddr = ":9193"
+	DefaultMaxViewers = 64
+	// NameSuffix tells the relayed game apart from the original when both
+	// are announced on the same network.
+	NameSuffix = " (relay)"
+
+	ViewOnlyMessage  = "This relay is view-only, join the master directly to play"
+	RelayFullMessage = "Relay has no room for more viewers"
+
+	joinTimeout      = 5 * time.Second
+	announceInterval = 1 * time.Second
+)
+
+type viewer struct {
+	id       int32
+	name     string
+	addr     *net.UDPAddr
+	lastSent time.Time
+	lastRecv time.Time
+}
+
+// Relay joins a game upstream as a VIEWER and serves its states to
+// downstream viewers, which see the relay as a master that only lets them
+// watch. Only the relay's own traffic reaches the real master. announce
+// holds the game as upstream lists it; viewers only ever see it with the
+// relay's own viewers added, see players.
+type Relay struct {
+	upstream   *network.Manager
+	gameName   string
+	playerName string
+	maxViewers int
+	conn       *net.UDPConn
+	groupAddr  *net.UDPAddr
+	mu         sync.Mutex
+	viewers    map[string]*viewer
+	pending    *network.PendingQueue
+	announce   *prt.GameAnnouncement
+	msgSeq     atomic.Int64
+	playerID   int32
+	delay      time.Duration
+	steers     atomic.Int64
+	done       chan struct{}
+	closeOnce  sync.Once
+	ended      chan struct{}
+	endOnce    sync.Once
+	wg         sync.WaitGroup
+}
+
+func New(upstream *network.Manager, gameName string, playerName string) *Relay {
+	r := &Relay{
+		upstream:   upstream,
+		gameName:   gameName,
+		playerName: playerName,
+		maxViewers: DefaultMaxViewers,
+		viewers:    make(map[string]*viewer),
+		pending:    network.NewPendingQueue(),
+		done:       make(chan struct{}),
+		ended:      make(chan struct{}),
+	}
+	r.msgSeq.Store(1)
+	upstream.SetGameStateListener(r)
+	upstream.SetErrorListener(r)
+	return r
+}
+
+func (r *Relay) SetMaxViewers(n int) {
+	r.maxViewers = n
+}
+
+// SetAnnounceGroup makes the relay announce its game on a multicast group,
+// so viewers on its own network find it without knowing its address.
+func (r *Relay) SetAnnounceGroup(group string) error {
+	addr, err := net.ResolveUDPAddr("udp", group)
+	if err != nil {
+		return fmt.Errorf("resolving announce group: %v", err)
+	}
+	r.groupAddr = addr
+	return nil
+}
+
+// Start joins the upstream game, which must already be in the game list,
+// and starts serving viewers on listenAddr.
+func (r *Relay) Start(listenAddr string) error {
+	info, ok := r.upstream.LookupGame(r.gameName)
+	if !ok {
+		return fmt.Errorf("game %q not found", r.gameName)
+	}
+	cfg := info.Announcement.GetConfig()
+	joined, err := r.upstream.SendJoinRequest(prt.PlayerType_ROBOT, r.playerName, info, prt.NodeRole_VIEWER)
+	if err != nil {
+		return err
+	}
+	select {
+	case r.playerID = <-joined:
+	case <-time.After(joinTimeout):
+		r.upstream.LeaveGame()
+		return fmt.Errorf("no answer from the master of %q", r.gameName)
+	}
+	r.upstream.SetGameAnnouncement(proto.Clone(info.Announcement).(*prt.GameAnnouncement))
+	r.upstream.SetActivityManager(cfg.GetStateDelayMs())
+
+	addr, err := net.ResolveUDPAddr("udp", listenAddr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return fmt.Errorf("binding relay socket: %v", err)
+	}
+	r.conn = conn
+	r.delay = time.Duration(cfg.GetStateDelayMs()) * time.Millisecond
+	r.announce = &prt.GameAnnouncement{
+		Players:  info.Announcement.GetPlayers(),
+		Config:   cfg,
+		CanJoin:  true,
+		GameName: r.gameName + NameSuffix,
+	}
+	r.wg.Add(2)
+	go r.listen()
+	go r.monitor()
+	log.Printf("Relaying '%s' as player %d on %s", r.gameName, r.playerID, conn.LocalAddr())
+	return nil
+}
+
+func (r *Relay) LocalAddr() *net.UDPAddr {
+	if r.conn == nil {
+		return nil
+	}
+	return r.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// Ended is closed once the upstream game is over.
+func (r *Relay) Ended() <-chan struct{} {
+	return r.ended
+}
+
+func (r *Relay) Viewers() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.viewers)
+}
+
+// RejectedSteers counts the steers viewers sent, which the relay never
+// passes on.
+func (r *Relay) RejectedSteers() int64 {
+	return r.steers.Load()
+}
+
+func (r *Relay) Close() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.upstream.LeaveGame()
+		if r.conn != nil {
+			r.conn.Close()
+		}
+		r.wg.Wait()
+	})
+}
+
+// OnGameStateReceived passes every new upstream state on to the viewers.
+func (r *Relay) OnGameStateReceived(state *prt.GameState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.announce == nil {
+		return
+	}
+	r.announce.Players = state.GetPlayers()
+	cfg := r.announce.GetConfig()
+	state = logic.EncodeState(state, cfg.GetWidth(), cfg.GetHeight())
+	state.Players = r.players()
+	for _, v := range r.viewers {
+		msg := &prt.GameMessage{
+			SenderId:   r.playerID,
+			ReceiverId: v.id,
+			Type:       &prt.GameMessage_State{State: &prt.GameMessage_StateMsg{State: state}},
+		}
+		r.sendReliable(msg, v.addr)
+	}
+}
+
+// OnErrorReceived shows the master's notices to the viewers as well, and
+// stops relaying once the game is over.
+func (r *Relay) OnErrorReceived(message string) {
+	log.Printf("Master: %s", message)
+	r.mu.Lock()
+	for _, v := range r.viewers {
+		r.sendReliable(&prt.GameMessage{
+			SenderId:   r.playerID,
+			ReceiverId: v.id,
+			Type:       &prt.GameMessage_Error{Error: &prt.GameMessage_ErrorMsg{ErrorMessage: message}},
+		}, v.addr)
+	}
+	r.mu.Unlock()
+	if message == network.GameEndedMessage || message == network.MasterLostMessage {
+		r.endOnce.Do(func() { close(r.ended) })
+	}
+}
+
+func (r *Relay) listen() {
+	defer r.wg.Done()
+	buf := make([]byte, 65536)
+	for {
+		n, addr, err := r.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Printf("Error reading from relay socket: %v", err)
+			continue
+		}
+		var msg prt.GameMessage
+		if err := proto.Unmarshal(buf[:n], &msg); err != nil || msg.GetType() == nil {
+			log.Printf("Rejected datagram from %s", addr)
+			continue
+		}
+		r.handle(&msg, addr)
+	}
+}
+
+// monitor pings idle viewers, resends unacked messages and drops viewers
+// that went quiet, on the same schedule as a master's activity manager.
+func (r *Relay) monitor() {
+	defer r.wg.Done()
+	interval := r.delay / 10
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var announce <-chan time.Time
+	if r.groupAddr != nil {
+		announceTicker := time.NewTicker(announceInterval)
+		defer announceTicker.Stop()
+		announce = announceTicker.C
+	}
+	for {
+		select {
+		case now := <-ticker.C:
+			r.mu.Lock()
+			r.pending.Retransmit(interval, r.write)
+			for key, v := range r.viewers {
+				if now.Sub(v.lastRecv) > r.delay*8/10 {
+					log.Printf("Viewer %s (%d) timed out", v.name, v.id)
+					r.removeViewer(key)
+					continue
+				}
+				if now.Sub(v.lastSent) > interval {
+					r.sendReliable(&prt.GameMessage{SenderId: r.playerID, Type: &prt.GameMessage_Ping{Ping: &prt.GameMessage_PingMsg{}}}, v.addr)
+				}
+			}
+			r.mu.Unlock()
+		case <-announce:
+			r.mu.Lock()
+			r.sendAnnouncement(r.groupAddr)
+			r.mu.Unlock()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+func (r *Relay) removeViewer(key string) {
+	if v := r.viewers[key]; v != nil {
+		r.pending.Drop(v.addr)
+	}
+	delete(r.viewers, key)
+}
@@ -0,0 +1,132 @@
+package relay_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"snake-game/internal/conformance"
+	"snake-game/internal/game/headless"
+	"snake-game/internal/network"
+	prt "snake-game/internal/proto/gen"
+	"snake-game/internal/relay"
+	"testing"
+	"time"
+)
+
+const (
+	testDelayMs = 200
+	waitFor     = 5 * time.Second
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv("NETWORK_TEST_LOGS") == "" {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+func newManager(t *testing.T) *network.Manager {
+	t.Helper()
+	mgr := network.NewNetworkManager(prt.NodeRole_NORMAL, nil)
+	mgr.SetMulticastGroup("239.255.255.250:0")
+	mgr.SetMulticastInterface("")
+	mgr.SetListenAddr("127.0.0.1:0")
+	if err := mgr.Start(); err != nil {
+		t.Skipf("cannot open sockets: %v", err)
+	}
+	t.Cleanup(mgr.Close)
+	return mgr
+}
+
+// startRelay hosts a game and relays it on a loopback port.
+func startRelay(t *testing.T) *relay.Relay {
+	t.Helper()
+	master := newManager(t)
+	host := headless.NewHost("relayed", "master", &prt.GameConfig{Width: 30, Height: 30, FoodStatic: 1, StateDelayMs: testDelayMs}, master)
+	host.Start()
+	t.Cleanup(host.Stop)
+
+	upstream := newManager(t)
+	if _, err := upstream.ConnectTo(fmt.Sprintf("127.0.0.1:%d", master.LocalAddr().Port), waitFor); err != nil {
+		t.Fatal(err)
+	}
+	r := relay.New(upstream, "relayed", "relay")
+	if err := r.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.Close)
+	return r
+}
+
+func joinRelay(t *testing.T, r *relay.Relay, peer *conformance.Peer) int32 {
+	t.Helper()
+	seq, err := peer.Send(&prt.GameMessage{Type: &prt.GameMessage_Join{Join: &prt.GameMessage_JoinMsg{
+		PlayerName: "viewer", GameName: "relayed" + relay.NameSuffix, RequestedRole: prt.NodeRole_VIEWER,
+	}}}, r.LocalAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ack, err := peer.Expect(waitFor, func(rcv *conformance.Received) bool {
+		return rcv.Msg.GetAck() != nil && rcv.Msg.GetMsgSeq() == seq
+	})
+	if err != nil {
+		t.Fatalf("join not acked: %v", err)
+	}
+	peer.SetPlayerID(ack.Msg.GetReceiverId())
+	return ack.Msg.GetReceiverId()
+}
+
+func TestRelayResendsUnackedStates(t *testing.T) {
+	r := startRelay(t)
+	peer, err := conformance.NewPeer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+	peer.SetAutoAck(false)
+	joinRelay(t, r, peer)
+
+	states := peer.Collect(testDelayMs*7/10*time.Millisecond, func(rcv *conformance.Received) bool { return rcv.Msg.GetState() != nil })
+	seen := make(map[int64]bool)
+	for _, rcv := range states {
+		if seen[rcv.Msg.GetMsgSeq()] {
+			return
+		}
+		seen[rcv.Msg.GetMsgSeq()] = true
+	}
+	t.Fatalf("%d states, none resent while unacked", len(states))
+}
+
+func TestRelayListsItsViewers(t *testing.T) {
+	r := startRelay(t)
+	peer, err := conformance.NewPeer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+	id := joinRelay(t, r, peer)
+
+	isViewer := func(players *prt.GamePlayers) bool {
+		for _, player := range players.GetPlayers() {
+			if player.GetId() == id {
+				return player.GetRole() == prt.NodeRole_VIEWER
+			}
+		}
+		return false
+	}
+	if _, err := peer.Expect(waitFor, func(rcv *conformance.Received) bool {
+		return isViewer(rcv.Msg.GetState().GetState().GetPlayers())
+	}); err != nil {
+		t.Errorf("viewer missing from the relayed states: %v", err)
+	}
+	if _, err := peer.Send(&prt.GameMessage{Type: &prt.GameMessage_Discover{Discover: &prt.GameMessage_DiscoverMsg{}}}, r.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := peer.Expect(waitFor, func(rcv *conformance.Received) bool {
+		games := rcv.Msg.GetAnnouncement().GetGames()
+		return len(games) == 1 && isViewer(games[0].GetPlayers())
+	}); err != nil {
+		t.Errorf("viewer missing from the relay's announcement: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package spectator
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	proto "snake-game/internal/proto/gen"
+	"sync"
+	"time"
+)
+
+//go:embed static
+var static embed.FS
+
+const (
+	clientBuffer   = 8
+	keepAliveEvery = 15 * time.Second
+)
+
+type frame struct {
+	GameName string          `json:"game_name"`
+	Config   json.RawMessage `json:"config"`
+	State    json.RawMessage `json:"state"`
+}
+
+type Server struct {
+	mu       sync.Mutex
+	gameName string
+	config   *proto.GameConfig
+	last     []byte
+	clients  map[chan []byte]struct{}
+	listener net.Listener
+	srv      *http.Server
+}
+
+func NewServer() *Server {
+	s := &Server{clients: make(map[chan []byte]struct{})}
+	mux := http.NewServeMux()
+	root, _ := fs.Sub(static, "static")
+	mux.Handle("/", http.FileServer(http.FS(root)))
+	mux.HandleFunc("/state", s.handleState)
+	mux.HandleFunc("/events", s.handleEvents)
+	s.srv = &http.Server{Handler: mux}
+	return s
+}
+
+func (s *Server) SetGame(gameName string, cfg *proto.GameConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gameName = gameName
+	s.config = cfg
+}
+
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("starting spectator server: %v", err)
+	}
+	s.listener = listener
+	go func() {
+		if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Spectator server stopped: %v", err)
+		}
+	}()
+	log.Printf("Spectator page available at http://%s/", listener.Addr())
+	return nil
+}
+
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+func (s *Server) Close() error {
+	s.mu.Lock()
+	for client := range s.clients {
+		close(client)
+		delete(s.clients, client)
+	}
+	s.mu.Unlock()
+	return s.srv.Close()
+}
+
+func (s *Server) OnGameStateReceived(state *proto.GameState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := s.encode(state)
+	if err != nil {
+		log.Printf("Error encoding state for spectators: %v", err)
+		return
+	}
+	s.last = data
+	for client := range s.clients {
+		select {
+		case client <- data:
+		default:
+		}
+	}
+}
+
+func (s *Server) encode(state *proto.GameState) ([]byte, error) {
+	stateJSON, err := protojson.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	configJSON := []byte("null")
+	if s.config != nil {
+		if configJSON, err = protojson.Marshal(s.config); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(frame{GameName: s.gameName, Config: configJSON, State: stateJSON})
+}
+
+func (s *Server) subscribe() (chan []byte, []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client := make(chan []byte, clientBuffer)
+	s.clients[client] = struct{}{}
+	return client, s.last
+}
+
+func (s *Server) unsubscribe(client chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[client]; ok {
+		delete(s.clients, client)
+		close(client)
+	}
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	last := s.last
+	s.mu.Unlock()
+	if last == nil {
+		http.Error(w, "no state yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(last)
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	client, last := s.subscribe()
+	defer s.unsubscribe(client)
+	if last != nil {
+		fmt.Fprintf(w, "data: %s\n\n", last)
+	}
+	flusher.Flush()
+	keepAlive := time.NewTicker(keepAliveEvery)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case data, ok := <-client:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package tournament
+
+import "math"
+
+const (
+	initialElo = 1500
+	eloK       = 32
+)
+
+// updateElo treats a game as every pair of bots playing each other, with the
+// better place winning. K is split across opponents so a game weighs the same
+// however many bots take part.
+func updateElo(ratings map[string]float64, results []Result) {
+	if len(results) < 2 {
+		return
+	}
+	k := eloK / float64(len(results)-1)
+	deltas := make([]float64, len(results))
+	for i := range results {
+		for j := range results {
+			if i == j {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (ratings[results[j].Bot]-ratings[results[i].Bot])/400))
+			actual := 0.5
+			switch {
+			case results[i].Place < results[j].Place:
+				actual = 1
+			case results[i].Place > results[j].Place:
+				actual = 0
+			}
+			deltas[i] += k * (actual - expected)
+		}
+	}
+	for i, r := range results {
+		ratings[r.Bot] += deltas[i]
+	}
+}
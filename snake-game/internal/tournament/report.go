@@ -0,0 +1,99 @@
+package tournament
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type BotStats struct {
+	Bot         string  `json:"bot"`
+	Games       int     `json:"games"`
+	Wins        int     `json:"wins"`
+	WinRate     float64 `json:"win_rate"`
+	AvgScore    float64 `json:"avg_score"`
+	AvgSurvival float64 `json:"avg_survival_ticks"`
+	Elo         float64 `json:"elo"`
+}
+
+type Report struct {
+	Games       int           `json:"games"`
+	Ticks       int           `json:"ticks"`
+	Elapsed     time.Duration `json:"elapsed_ns"`
+	TicksPerSec float64       `json:"ticks_per_second"`
+	Bots        []BotStats    `json:"bots"`
+}
+
+func newReport(o Options, games []*Game, elapsed time.Duration) *Report {
+	stats := make(map[string]*BotStats)
+	ratings := make(map[string]float64)
+	for _, name := range o.Bots {
+		stats[name] = &BotStats{Bot: name}
+		ratings[name] = initialElo
+	}
+	r := &Report{Games: len(games), Elapsed: elapsed}
+	for _, game := range games {
+		r.Ticks += game.Ticks
+		for _, res := range game.Results {
+			s := stats[res.Bot]
+			s.Games++
+			if res.Place == 1 {
+				s.Wins++
+			}
+			s.AvgScore += float64(res.Score)
+			s.AvgSurvival += float64(res.Survived)
+		}
+		updateElo(ratings, game.Results)
+	}
+	if elapsed > 0 {
+		r.TicksPerSec = float64(r.Ticks) / elapsed.Seconds()
+	}
+	for _, name := range o.Bots {
+		s := stats[name]
+		if s.Games > 0 {
+			s.WinRate = float64(s.Wins) / float64(s.Games)
+			s.AvgScore /= float64(s.Games)
+			s.AvgSurvival /= float64(s.Games)
+		}
+		s.Elo = ratings[name]
+		r.Bots = append(r.Bots, *s)
+	}
+	sort.SliceStable(r.Bots, func(i, j int) bool { return r.Bots[i].Elo > r.Bots[j].Elo })
+	return r
+}
+
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "%d games, %d ticks in %s (%.0f ticks/s)\n", r.Games, r.Ticks, r.Elapsed.Round(time.Millisecond), r.TicksPerSec)
+	fmt.Fprintf(w, "%-12s %7s %8s %9s %9s %7s\n", "bot", "games", "win rate", "avg score", "avg ticks", "elo")
+	for _, s := range r.Bots {
+		fmt.Fprintf(w, "%-12s %7d %7.1f%% %9.2f %9.1f %7.0f\n", s.Bot, s.Games, 100*s.WinRate, s.AvgScore, s.AvgSurvival, s.Elo)
+	}
+}
+
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"bot", "games", "wins", "win_rate", "avg_score", "avg_survival_ticks", "elo"})
+	for _, s := range r.Bots {
+		cw.Write([]string{
+			s.Bot,
+			strconv.Itoa(s.Games),
+			strconv.Itoa(s.Wins),
+			strconv.FormatFloat(s.WinRate, 'f', 4, 64),
+			strconv.FormatFloat(s.AvgScore, 'f', 3, 64),
+			strconv.FormatFloat(s.AvgSurvival, 'f', 1, 64),
+			strconv.FormatFloat(s.Elo, 'f', 1, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
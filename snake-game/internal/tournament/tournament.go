@@ -0,0 +1,184 @@
+package tournament
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"snake-game/internal/game/bots"
+	"snake-game/internal/game/logic"
+	proto "snake-game/internal/proto/gen"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Field struct {
+	Width  int32
+	Height int32
+}
+
+type Options struct {
+	Bots     []string
+	Fields   []Field
+	Foods    []int32
+	Games    int
+	MaxTicks int
+	Workers  int
+	Seed     uint64
+}
+
+// Result is one bot's outcome in one game.
+type Result struct {
+	Bot      string
+	Score    int32
+	Survived int
+	Place    int
+}
+
+type Game struct {
+	Index   int
+	Field   Field
+	Food    int32
+	Ticks   int
+	Results []Result
+}
+
+// setup describes one game before it is played: every game seats each bot
+// once, in a seeded random order so placement does not favour any of them.
+type setup struct {
+	index int
+	field Field
+	food  int32
+	seed  uint64
+}
+
+func (o Options) setups() []setup {
+	setups := make([]setup, 0, o.Games)
+	for i := 0; i < o.Games; i++ {
+		setups = append(setups, setup{
+			index: i,
+			field: o.Fields[i%len(o.Fields)],
+			food:  o.Foods[(i/len(o.Fields))%len(o.Foods)],
+			seed:  o.Seed + uint64(i),
+		})
+	}
+	return setups
+}
+
+func Run(o Options) (*Report, error) {
+	if len(o.Bots) == 0 || len(o.Fields) == 0 || len(o.Foods) == 0 || o.Games <= 0 {
+		return nil, fmt.Errorf("tournament needs bots, fields, food settings and at least one game")
+	}
+	strategies := make([]bots.Strategy, len(o.Bots))
+	for i, name := range o.Bots {
+		s, err := bots.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		strategies[i] = s
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.NumCPU()
+	}
+
+	setups := o.setups()
+	games := make([]*Game, len(setups))
+	jobs := make(chan setup)
+	var wg sync.WaitGroup
+	started := time.Now()
+	for w := 0; w < o.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range jobs {
+				games[s.index] = play(s, o.Bots, strategies, o.MaxTicks)
+			}
+		}()
+	}
+	for _, s := range setups {
+		jobs <- s
+	}
+	close(jobs)
+	wg.Wait()
+	return newReport(o, games, time.Since(started)), nil
+}
+
+func play(s setup, names []string, strategies []bots.Strategy, maxTicks int) *Game {
+	rnd := rand.New(rand.NewPCG(s.seed, 1))
+	gl := logic.NewGameLogic(&proto.GameConfig{Width: s.field.Width, Height: s.field.Height, FoodStatic: s.food})
+	gl.Seed(s.seed)
+
+	seats := rnd.Perm(len(names))
+	ids := make([]int32, len(names))
+	for _, seat := range seats {
+		ids[seat] = int32(seat + 1)
+		gl.AddPlayer(gl.NewPlayer(names[seat], proto.PlayerType_ROBOT, proto.NodeRole_NORMAL, ids[seat]))
+	}
+	gl.Init()
+
+	survived := make([]int, len(names))
+	alive := func(i int) bool {
+		snake := gl.GetSnakeByPlayerID(ids[i])
+		return snake != nil && snake.GetState() == proto.GameState_Snake_ALIVE
+	}
+	tick := 0
+	for ; tick < maxTicks; tick++ {
+		left := 0
+		for i := range names {
+			if alive(i) {
+				left++
+			}
+		}
+		if left == 0 || len(names) > 1 && left == 1 {
+			break
+		}
+		state := gl.GetState()
+		for i, strategy := range strategies {
+			if !alive(i) {
+				continue
+			}
+			dir := strategy.Decide(bots.View{State: state, Field: gl.GetField(), PlayerID: ids[i], Rand: rnd})
+			gl.SteerSnake(ids[i], dir)
+		}
+		gl.Update()
+		for i := range names {
+			if alive(i) {
+				survived[i] = tick + 1
+			}
+		}
+	}
+
+	game := &Game{Index: s.index, Field: s.field, Food: s.food, Ticks: tick}
+	for i, name := range names {
+		player, _ := gl.GetPlayer(ids[i])
+		game.Results = append(game.Results, Result{Bot: name, Score: player.GetScore(), Survived: survived[i]})
+	}
+	rank(game.Results)
+	return game
+}
+
+// rank places bots by how long they lasted, then by score. Ties share a
+// place, so two snakes dying in the same head-on collision both lose.
+func rank(results []Result) {
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	better := func(a, b Result) bool {
+		if a.Survived != b.Survived {
+			return a.Survived > b.Survived
+		}
+		return a.Score > b.Score
+	}
+	sort.SliceStable(order, func(i, j int) bool { return better(results[order[i]], results[order[j]]) })
+	for pos, i := range order {
+		place := pos + 1
+		if pos > 0 {
+			prev := results[order[pos-1]]
+			if !better(prev, results[i]) {
+				place = prev.Place
+			}
+		}
+		results[i].Place = place
+	}
+}